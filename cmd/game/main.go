@@ -1,15 +1,25 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"os"
 	"time"
 
+	"gorl/fw/core/backend"
 	"gorl/fw/core/gem"
+	actions "gorl/fw/core/input/input_event"
 	input "gorl/fw/core/input/input_handling"
 	"gorl/fw/core/logging"
+	"gorl/fw/core/loop"
 	"gorl/fw/core/render"
 	"gorl/fw/core/settings"
 	"gorl/fw/core/store"
+	"gorl/fw/modules/event"
+	"gorl/fw/modules/live_settings"
+	"gorl/fw/modules/picking"
+	"gorl/fw/modules/scenes"
 	"gorl/fw/physics"
 	"gorl/game"
 
@@ -20,20 +30,43 @@ import (
 )
 
 func main() {
+	// command line flags
+	headless := flag.Bool("headless", false, "run without a window, rendering or device input")
+	frames := flag.Int("frames", 0, "exit after this many frames, 0 runs until the game quits")
+	record := flag.String("record", "", "record input and frame times to this file")
+	replay := flag.String("replay", "", "replay input and frame times from a file written by --record")
+	watchSettings := flag.Bool("watch-settings", false, "reload the settings files when they change on disk")
+	// every setting can be overridden by a flag of the same name, e.g.
+	// --targetFps 30.
+	settings.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
 	// PRE-INIT
-	go func() {
-		err := http.ListenAndServe("localhost:6969", nil)
-		if err != nil {
-			panic(err)
-		}
-	}()
+	// the profiling server is only useful with a window, and would collide
+	// with parallel headless runs in CI.
+	if !*headless {
+		go func() {
+			err := http.ListenAndServe("localhost:6969", nil)
+			if err != nil {
+				panic(err)
+			}
+		}()
+	}
 
-	// settings
-	settings_path := "settings.json"
-	err := settings.LoadSettings(settings_path)
+	// settings, layered from the defaults, the shipped file, the player's
+	// file, environment variables and flags.
+	err := settings.Load(settings.Sources{
+		File:      "settings.json",
+		UserFile:  "user_settings.json",
+		EnvPrefix: "GORL_",
+		Flags:     flag.CommandLine,
+	})
+	settings.EnableHotReload(*watchSettings)
 	if err != nil {
 		fmt.Println("Error loading settings:", err)
 		fmt.Println("Using fallback settings.")
+		// the files are still watched and saved to, so fixing them while
+		// the game runs loads them.
 		settings.UseFallbackSettings()
 	}
 
@@ -47,21 +80,35 @@ func main() {
 	}
 
 	// INITIALIZATION
-	// raylib window
-	rl.InitWindow(
-		int32(settings.CurrentSettings().ScreenWidth),
-		int32(settings.CurrentSettings().ScreenHeight),
-		settings.CurrentSettings().Title)
-	defer rl.CloseWindow()
-	rl.SetTargetFPS(int32(settings.CurrentSettings().TargetFps))
-
-	// rendering
-	render.Init(rl.NewVector2(
-		float32(settings.CurrentSettings().ScreenWidth),
-		float32(settings.CurrentSettings().ScreenHeight)))
-	defer render.Deinit()
-
-	logging.Info("Rendering initialized.")
+	// backend (window, rendering and input)
+	if *headless {
+		backend.Use(backend.NewHeadlessBackend(1.0 / float32(settings.CurrentSettings().TargetFps)))
+	} else {
+		backend.Use(backend.NewRaylibBackend())
+	}
+	if *replay != "" {
+		recording, err := input.LoadRecording(*replay)
+		if err != nil {
+			logging.Fatal("Failed to load input recording: %v", err)
+		}
+		backend.Use(backend.NewReplayBackend(backend.Current(), recording))
+	} else if *record != "" {
+		backend.Use(backend.NewRecordingBackend(backend.Current(), *record))
+	}
+	backend.Current().Init(
+		settings.CurrentSettings().Title,
+		rl.NewVector2(
+			float32(settings.CurrentSettings().ScreenWidth),
+			float32(settings.CurrentSettings().ScreenHeight)),
+		int32(settings.CurrentSettings().TargetFps))
+	defer backend.Current().Deinit()
+
+	logging.Info("Backend initialized, headless: %v, recording: %q, replaying: %q", *headless, *record, *replay)
+
+	// apply the settings to the window, renderer, audio and input, now and
+	// whenever they change.
+	live_settings.Init()
+	defer live_settings.Deinit()
 
 	// initialize audio
 	//audio.InitAudio()
@@ -71,8 +118,12 @@ func main() {
 	//collision.InitCollision()
 	//defer collision.DeinitCollision()
 
+	// game loop timing. FixedUpdate and physics share the same timestep.
+	const fixedTimestep = 1.0 / 60.0
+	loop.Init(fixedTimestep, backend.Current())
+
 	// physics
-	physics.InitPhysics((1.0 / 60.0), rl.Vector2Zero(), (1.0 / 32.0))
+	physics.InitPhysics(fixedTimestep, rl.Vector2Zero(), (1.0 / 32.0))
 	defer physics.DeinitPhysics()
 
 	gem.Init()
@@ -111,6 +162,16 @@ func main() {
 	//rl.DisableCursor()
 	game.Init()
 
+	// input bindings changed by the player. this happens after game.Init,
+	// so the actions registered by the game can be restored as well.
+	bindings_path := "bindings.json"
+	err = actions.LoadBindings(bindings_path)
+	if err == nil {
+		logging.Info("Input bindings loaded successfully.")
+	} else if !errors.Is(err, os.ErrNotExist) {
+		logging.Warning("Input bindings loading unsuccessful: %v", err)
+	}
+
 	// GAME LOOP
 	//rl.SetExitKey(rl.KeyEnd) // Set a key to exit the game
 	shouldExit := false
@@ -119,35 +180,80 @@ func main() {
 	frameStart := time.Now()
 	var frameTime time.Duration = 0
 
-	for !shouldExit {
+	for frame := 0; !shouldExit; frame++ {
 		frameStart = time.Now()
 
-		shouldFixedUpdate := physics.Update()
-		drawables, inputReceivers := gem.Traverse(shouldFixedUpdate)
+		// pick up edited settings files, if enabled by --watch-settings.
+		if err := settings.PollFiles(); err != nil {
+			logging.Error("Failed to reload settings: %v", err)
+		}
 
-		//scenes.UpdateScenes() // TODO: rework scenes to be more clear
-		//scenes.FixedUpdateScenes()
+		// advance scene stack changes and their transitions, before the
+		// entities of the changed scenes are processed.
+		scenes.Update()
 
-		rl.BeginDrawing()
+		// run as many fixed steps as the elapsed time demands, which may
+		// also be none at all.
+		// physics is halted while the game is paused; entities that keep
+		// processing during pause still receive their FixedUpdate. see
+		// store.AppState.Paused.
+		fixedSteps := loop.Tick()
+		appState, ok := store.Get[*store.AppState]()
+		paused := ok && appState.Paused
+		for i := 0; i < fixedSteps; i++ {
+			if !paused {
+				physics.Step()
+			}
+			gem.FixedTraverse()
+		}
+		drawables, inputReceivers := gem.Traverse()
 
-		render.Draw(drawables)
+		backend.Current().BeginFrame()
+
+		drawn := render.Draw(drawables)
 
 		// input is processed at the end of the frame, because here we know in
 		// what order the entities were drawn, and can be sure whatever the
 		// user clicked was really visible at the front.
-		//inputEventReceivers := append(inputReceivers, drawableInputReceivers...)
-		input.HandleInputEvents(inputReceivers)
+		// each receiver gets events through the camera that drew it.
+		input.HandleInputEvents(inputReceivers, drawn)
+		// pointer events for the pickable entities under the cursor.
+		picking.Update(inputReceivers, drawn)
+
+		// publish the events queued during this frame and the delayed events
+		// that are due, before the structural changes their handlers make are
+		// applied.
+		if err := event.Flush(loop.GetDeltaTime()); err != nil {
+			logging.Error("Event handlers failed: %v", err)
+		}
+
+		// apply structural changes made during this frame, including
+		// entities queued for removal.
+		gem.FlushCommands()
 
 		// Draw Debug Info
-		DrawDebugInfo(frameTime)
+		if !backend.IsHeadless() {
+			DrawDebugInfo(frameTime)
+		}
 
-		rl.EndDrawing()
+		backend.Current().EndFrame()
 
 		//audio.Update()
-		frameTime = time.Since(frameStart) // calculate after rl.EndDrawing() to include rendering time
+		frameTime = time.Since(frameStart) // calculate after EndFrame() to include rendering time
 
-		appState, ok := store.Get[*store.AppState]()
-		shouldExit = rl.WindowShouldClose() || (!ok || appState.ShouldQuit)
+		appState, ok = store.Get[*store.AppState]()
+		shouldExit = backend.Current().ShouldClose() || (!ok || appState.ShouldQuit)
+		if *frames > 0 && frame+1 >= *frames {
+			shouldExit = true
+		}
+	}
+
+	if recorder, ok := backend.Current().(*backend.RecordingBackend); ok {
+		if err := recorder.Save(); err != nil {
+			logging.Error("Failed to save input recording: %v", err)
+		} else {
+			logging.Info("Input recording saved to %v", *record)
+		}
 	}
 
 	//scenes.Sm.DisableAllScenes()
@@ -42,7 +42,7 @@ func CheckCollisionLineCircle(line_start, line_end, circle_origin rl.Vector2, ci
 	h := math.Sqrt(float64(circle_radius*circle_radius) - float64(distance*distance))
 
 	direction := rl.NewVector2(line_end.X-line_start.X, line_end.Y-line_start.Y)
-	direction = rl.Vector2DivideV(
+	direction = rl.Vector2Divide(
 		direction,
 		rl.NewVector2(
 			float32(math.Sqrt(float64(direction.X*direction.X+direction.Y*direction.Y))),
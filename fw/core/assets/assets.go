@@ -0,0 +1,95 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gorl/fw/core/render"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// cache holds the content of asset files read from disk, and the GPU
+// resources created from them.
+type cache struct {
+	mu       sync.RWMutex
+	files    map[string][]byte
+	textures map[string]rl.Texture2D // only touched from the main thread
+}
+
+var assetCache = &cache{
+	files:    make(map[string][]byte),
+	textures: make(map[string]rl.Texture2D),
+}
+
+// ReadFile returns the content of the asset file at path, reading it from
+// disk unless it was read or preloaded before. Safe to call from any
+// goroutine.
+func ReadFile(path string) ([]byte, error) {
+	assetCache.mu.RLock()
+	content, ok := assetCache.files[path]
+	assetCache.mu.RUnlock()
+	if ok {
+		return content, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	assetCache.mu.Lock()
+	assetCache.files[path] = content
+	assetCache.mu.Unlock()
+	return content, nil
+}
+
+// IsLoaded returns true if the asset file at path is in the cache.
+func IsLoaded(path string) bool {
+	assetCache.mu.RLock()
+	defer assetCache.mu.RUnlock()
+	_, ok := assetCache.files[path]
+	return ok
+}
+
+// LoadTexture returns the texture stored in the image file at path. The
+// texture is created once and shared by all callers, so it must not be
+// unloaded directly, use Unload instead. Must be called from the main thread.
+//
+// When running headless, a zero value texture is returned.
+func LoadTexture(path string) (rl.Texture2D, error) {
+	if texture, ok := assetCache.textures[path]; ok {
+		return texture, nil
+	}
+
+	content, err := ReadFile(path)
+	if err != nil {
+		return rl.Texture2D{}, err
+	}
+	if render.IsHeadless() {
+		return rl.Texture2D{}, nil
+	}
+
+	fileType := strings.ToLower(filepath.Ext(path))
+	image := rl.LoadImageFromMemory(fileType, content, int32(len(content)))
+	defer rl.UnloadImage(image)
+	texture := rl.LoadTextureFromImage(image)
+	assetCache.textures[path] = texture
+	return texture, nil
+}
+
+// Unload removes the asset at path from the cache, unloading its GPU
+// resources if any were created.
+func Unload(path string) {
+	if texture, ok := assetCache.textures[path]; ok {
+		if !render.IsHeadless() {
+			rl.UnloadTexture(texture)
+		}
+		delete(assetCache.textures, path)
+	}
+
+	assetCache.mu.Lock()
+	delete(assetCache.files, path)
+	assetCache.mu.Unlock()
+}
@@ -0,0 +1,70 @@
+package assets
+
+import (
+	"errors"
+	"sync"
+)
+
+// Preload reads a list of asset files into the cache on a background
+// goroutine. Poll it from the main loop to report progress.
+type Preload struct {
+	mu     sync.Mutex
+	total  int
+	loaded int
+	errs   []error
+	done   chan struct{}
+}
+
+// StartPreload starts reading the given asset files in the background.
+// Files that are already cached count as loaded right away.
+func StartPreload(paths []string) *Preload {
+	p := &Preload{total: len(paths), done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		for _, path := range paths {
+			_, err := ReadFile(path)
+			p.mu.Lock()
+			p.loaded++
+			if err != nil {
+				p.errs = append(p.errs, err)
+			}
+			p.mu.Unlock()
+		}
+	}()
+	return p
+}
+
+// Progress returns the share of files read so far, from 0 to 1.
+func (p *Preload) Progress() float32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.total == 0 {
+		return 1
+	}
+	return float32(p.loaded) / float32(p.total)
+}
+
+// IsDone returns true once all files have been read, or failed to read.
+func (p *Preload) IsDone() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until all files have been read and returns the combined
+// errors, if any.
+func (p *Preload) Wait() error {
+	<-p.done
+	return p.Err()
+}
+
+// Err returns the errors of the files that could not be read so far, joined
+// into one.
+func (p *Preload) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(p.errs...)
+}
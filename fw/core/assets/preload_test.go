@@ -0,0 +1,36 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreload(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}
+	for _, path := range paths {
+		if err := os.WriteFile(path, []byte(path), 0644); err != nil {
+			t.Fatalf("failed to write %v: %v", path, err)
+		}
+	}
+
+	preload := StartPreload(append(paths, filepath.Join(dir, "missing.txt")))
+	if err := preload.Wait(); err == nil {
+		t.Errorf("expected an error for the missing file")
+	}
+	if preload.Progress() != 1 || !preload.IsDone() {
+		t.Errorf("expected the preload to be done, progress %v", preload.Progress())
+	}
+
+	for _, path := range paths {
+		if !IsLoaded(path) {
+			t.Errorf("expected %v to be cached", path)
+		}
+		// removing the file shows the cached content is used.
+		os.Remove(path)
+		if content, err := ReadFile(path); err != nil || string(content) != path {
+			t.Errorf("unexpected cached content %q, err %v", content, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package backend
+
+import (
+	input "gorl/fw/core/input/input_handling"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Backend is the platform layer the main loop runs on. It owns the window,
+// the frame clock, the sink that drawables are rendered to and the source
+// input events are read from.
+type Backend interface {
+	// Window
+	Init(title string, screenSize rl.Vector2, targetFps int32)
+	Deinit()
+	ShouldClose() bool
+	// SetWindowSize, SetFullscreen, SetVSync and SetTargetFps change the
+	// window at runtime, e.g. when the settings change.
+	SetWindowSize(size rl.Vector2)
+	SetFullscreen(fullscreen bool)
+	SetVSync(enabled bool)
+	SetTargetFps(targetFps int32)
+
+	// Frame clock
+	// GetFrameTime returns the duration of the last frame in seconds.
+	GetFrameTime() float32
+
+	// Draw sink
+	// Everything drawn between BeginFrame and EndFrame ends up on screen.
+	BeginFrame()
+	EndFrame()
+
+	// Input source
+	input.InputSource
+
+	// IsHeadless returns true if the backend has no window or GPU.
+	IsHeadless() bool
+}
+
+// currentBackend is the backend in use, set by Use.
+var currentBackend Backend = NewRaylibBackend()
+
+// Use sets the backend the game runs on. This must be called before
+// Current().Init().
+func Use(b Backend) {
+	currentBackend = b
+}
+
+// Current returns the backend in use.
+func Current() Backend {
+	return currentBackend
+}
+
+// GetFrameTime returns the duration of the last frame in seconds, as measured
+// by the current backend. Entities should use this instead of
+// rl.GetFrameTime, which is always zero when running headless.
+func GetFrameTime() float32 {
+	return currentBackend.GetFrameTime()
+}
+
+// IsHeadless returns true if the current backend has no window or GPU.
+func IsHeadless() bool {
+	return currentBackend.IsHeadless()
+}
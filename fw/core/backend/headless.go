@@ -0,0 +1,90 @@
+package backend
+
+import (
+	input "gorl/fw/core/input/input_handling"
+	"gorl/fw/core/render"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var _ Backend = (*HeadlessBackend)(nil)
+
+// HeadlessBackend runs the game without a window or GPU. Every frame takes
+// exactly frameTime seconds, and input only comes from events queued on the
+// backend, so runs are reproducible.
+type HeadlessBackend struct {
+	*input.QueuedInputSource
+
+	frameTime  float32
+	frameCount int
+	closed     bool
+}
+
+// NewHeadlessBackend creates a new headless backend where every frame lasts
+// frameTime seconds.
+func NewHeadlessBackend(frameTime float32) *HeadlessBackend {
+	return &HeadlessBackend{
+		QueuedInputSource: input.NewQueuedInputSource(),
+		frameTime:         frameTime,
+	}
+}
+
+// Init initializes a headless renderer and routes queued input events to the
+// input package. The title and target fps are ignored.
+func (b *HeadlessBackend) Init(title string, screenSize rl.Vector2, targetFps int32) {
+	render.InitHeadless(screenSize)
+	input.SetInputSource(b)
+}
+
+// Deinit deinitializes the renderer.
+func (b *HeadlessBackend) Deinit() {
+	render.Deinit()
+}
+
+// ShouldClose returns true once Close was called.
+func (b *HeadlessBackend) ShouldClose() bool {
+	return b.closed
+}
+
+// Close makes the next call to ShouldClose return true.
+func (b *HeadlessBackend) Close() {
+	b.closed = true
+}
+
+// SetWindowSize tells the renderer about the new size, so the screen is laid
+// out as it would be in a window.
+func (b *HeadlessBackend) SetWindowSize(size rl.Vector2) {
+	render.SetWindowSize(size)
+}
+
+// SetFullscreen does nothing, there is no window.
+func (b *HeadlessBackend) SetFullscreen(fullscreen bool) {}
+
+// SetVSync does nothing, there is no monitor.
+func (b *HeadlessBackend) SetVSync(enabled bool) {}
+
+// SetTargetFps does nothing, every frame takes the fixed frame time.
+func (b *HeadlessBackend) SetTargetFps(targetFps int32) {}
+
+// GetFrameTime returns the fixed frame time of the backend in seconds.
+func (b *HeadlessBackend) GetFrameTime() float32 {
+	return b.frameTime
+}
+
+// GetFrameCount returns the number of frames that have been completed.
+func (b *HeadlessBackend) GetFrameCount() int {
+	return b.frameCount
+}
+
+// BeginFrame does nothing, there is nothing to draw to.
+func (b *HeadlessBackend) BeginFrame() {}
+
+// EndFrame completes the current frame.
+func (b *HeadlessBackend) EndFrame() {
+	b.frameCount++
+}
+
+// IsHeadless always returns true.
+func (b *HeadlessBackend) IsHeadless() bool {
+	return true
+}
@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"testing"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	input_event "gorl/fw/core/input/input_event"
+	input "gorl/fw/core/input/input_handling"
+	"gorl/fw/core/math"
+	"gorl/fw/core/render"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// countingEntity counts how often it was updated, drawn and sent input.
+type countingEntity struct {
+	*entities.Entity
+	updates int
+	draws   int
+	events  int
+	camera  input_event.Viewport
+}
+
+func (ent *countingEntity) Update() { ent.updates++ }
+func (ent *countingEntity) Draw()   { ent.draws++ }
+func (ent *countingEntity) OnInputEvent(event *input_event.InputEvent) bool {
+	ent.events++
+	ent.camera = event.GetCamera()
+	return true
+}
+
+// TestHeadlessBackendDrivesEntities runs a few frames of the main loop
+// without a window and checks that entities are updated and receive queued
+// input, but are never drawn.
+func TestHeadlessBackendDrivesEntities(t *testing.T) {
+	b := NewHeadlessBackend(1.0 / 60.0)
+	Use(b)
+	b.Init("test", rl.NewVector2(320, 180), 60)
+	defer b.Deinit()
+
+	gem.Init()
+	defer gem.Deinit()
+
+	cam := render.NewCamera(rl.Vector2Zero(), rl.Vector2Zero(), rl.NewVector2(320, 180), rl.Vector2Zero(), math.Flag0)
+	defer cam.Destroy()
+
+	ent := &countingEntity{Entity: entities.NewEntity("counting", rl.Vector2Zero(), 0, rl.Vector2One())}
+	gem.Append(gem.GetRoot(), ent)
+
+	b.Queue(input_event.NewInputEvent(input_event.ActionClickDown, rl.NewVector2(10, 10)))
+
+	const frames = 10
+	for i := 0; i < frames; i++ {
+		b.BeginFrame()
+		drawables, processed := gem.Traverse()
+		drawn := render.Draw(drawables)
+		input.HandleInputEvents(processed, drawn)
+		b.EndFrame()
+	}
+
+	if b.GetFrameCount() != frames {
+		t.Errorf("expected %d frames, got %d", frames, b.GetFrameCount())
+	}
+	if GetFrameTime() != float32(1.0/60.0) {
+		t.Errorf("expected a fixed frame time of 1/60s, got %f", GetFrameTime())
+	}
+	if ent.updates != frames {
+		t.Errorf("expected %d updates, got %d", frames, ent.updates)
+	}
+	if ent.events != 1 {
+		t.Errorf("expected the queued event to be delivered once, got %d", ent.events)
+	}
+	if ent.camera != cam {
+		t.Errorf("expected the event to arrive through the camera, got %v", ent.camera)
+	}
+	if ent.draws != 0 {
+		t.Errorf("expected no draw calls when headless, got %d", ent.draws)
+	}
+}
@@ -0,0 +1,100 @@
+package backend
+
+import (
+	input_event "gorl/fw/core/input/input_event"
+	input "gorl/fw/core/input/input_handling"
+	"gorl/fw/core/render"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var _ Backend = (*RaylibBackend)(nil)
+
+// RaylibBackend runs the game in a raylib window.
+type RaylibBackend struct {
+	input.InputSource
+}
+
+// NewRaylibBackend creates a new backend that runs in a raylib window.
+func NewRaylibBackend() *RaylibBackend {
+	return &RaylibBackend{
+		InputSource: input.NewRaylibInputSource(),
+	}
+}
+
+// CaptureTrigger returns the trigger of the last key or mouse button pressed
+// in the window, see input.ListenForNextInput.
+func (b *RaylibBackend) CaptureTrigger() (input_event.Trigger, bool) {
+	if capturer, ok := b.InputSource.(input.TriggerCapturer); ok {
+		return capturer.CaptureTrigger()
+	}
+	return input_event.Trigger{}, false
+}
+
+// Init opens the window, initializes the renderer and routes input from the
+// window to the input package.
+func (b *RaylibBackend) Init(title string, screenSize rl.Vector2, targetFps int32) {
+	rl.InitWindow(int32(screenSize.X), int32(screenSize.Y), title)
+	rl.SetTargetFPS(targetFps)
+	render.Init(screenSize)
+	input.SetInputSource(b)
+}
+
+// Deinit deinitializes the renderer and closes the window.
+func (b *RaylibBackend) Deinit() {
+	render.Deinit()
+	rl.CloseWindow()
+}
+
+// ShouldClose returns true if the user requested to close the window.
+func (b *RaylibBackend) ShouldClose() bool {
+	return rl.WindowShouldClose()
+}
+
+// SetWindowSize resizes the window. The screen is scaled to fit the new size.
+func (b *RaylibBackend) SetWindowSize(size rl.Vector2) {
+	rl.SetWindowSize(int(size.X), int(size.Y))
+	render.SetWindowSize(size)
+}
+
+// SetFullscreen switches between fullscreen and windowed mode. In fullscreen,
+// the monitor is switched to the size of the window.
+func (b *RaylibBackend) SetFullscreen(fullscreen bool) {
+	if rl.IsWindowFullscreen() != fullscreen {
+		rl.ToggleFullscreen()
+	}
+}
+
+// SetVSync enables or disables waiting for the vertical blank of the monitor.
+func (b *RaylibBackend) SetVSync(enabled bool) {
+	if enabled {
+		rl.SetWindowState(rl.FlagVsyncHint)
+	} else {
+		rl.ClearWindowState(rl.FlagVsyncHint)
+	}
+}
+
+// SetTargetFps sets the frame rate the game is limited to.
+func (b *RaylibBackend) SetTargetFps(targetFps int32) {
+	rl.SetTargetFPS(targetFps)
+}
+
+// GetFrameTime returns the duration of the last frame in seconds.
+func (b *RaylibBackend) GetFrameTime() float32 {
+	return rl.GetFrameTime()
+}
+
+// BeginFrame begins drawing to the window.
+func (b *RaylibBackend) BeginFrame() {
+	rl.BeginDrawing()
+}
+
+// EndFrame ends drawing to the window and swaps the buffers.
+func (b *RaylibBackend) EndFrame() {
+	rl.EndDrawing()
+}
+
+// IsHeadless always returns false.
+func (b *RaylibBackend) IsHeadless() bool {
+	return false
+}
@@ -0,0 +1,105 @@
+package backend
+
+import (
+	input_event "gorl/fw/core/input/input_event"
+	input "gorl/fw/core/input/input_handling"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var _ Backend = (*RecordingBackend)(nil)
+
+// RecordingBackend runs the game on another backend and records its input
+// and frame times, so the run can be replayed with a ReplayBackend.
+type RecordingBackend struct {
+	Backend
+	source *input.RecordingSource
+	path   string
+}
+
+// NewRecordingBackend creates a backend recording the run on the given
+// backend. Save writes the recording to path.
+func NewRecordingBackend(b Backend, path string) *RecordingBackend {
+	return &RecordingBackend{
+		Backend: b,
+		source:  input.NewRecordingSource(b, b),
+		path:    path,
+	}
+}
+
+// Init initializes the wrapped backend and routes its input to the input
+// package through the recorder.
+func (b *RecordingBackend) Init(title string, screenSize rl.Vector2, targetFps int32) {
+	b.Backend.Init(title, screenSize, targetFps)
+	input.SetInputSource(b)
+}
+
+// Save writes the frames recorded so far to the recording's path.
+func (b *RecordingBackend) Save() error {
+	return b.source.GetRecording().Save(b.path)
+}
+
+// PollInputEvents returns the events of the wrapped backend and records them.
+func (b *RecordingBackend) PollInputEvents() []*input_event.InputEvent {
+	return b.source.PollInputEvents()
+}
+
+// CaptureTrigger passes on captured triggers of the wrapped backend.
+func (b *RecordingBackend) CaptureTrigger() (input_event.Trigger, bool) {
+	return b.source.CaptureTrigger()
+}
+
+// GetRecording returns the frames recorded so far.
+func (b *RecordingBackend) GetRecording() *input.Recording {
+	return b.source.GetRecording()
+}
+
+var _ Backend = (*ReplayBackend)(nil)
+
+// ReplayBackend runs the game on another backend, but reads input and frame
+// times from a recording instead. Entities that only depend on these end up
+// in the same state as in the recorded run. The backend closes once the
+// recording is over.
+type ReplayBackend struct {
+	Backend
+	source *input.ReplaySource
+}
+
+// NewReplayBackend creates a backend replaying the recording on the given
+// backend, usually a HeadlessBackend.
+func NewReplayBackend(b Backend, recording *input.Recording) *ReplayBackend {
+	return &ReplayBackend{
+		Backend: b,
+		source:  input.NewReplaySource(recording),
+	}
+}
+
+// Init initializes the wrapped backend and routes the recorded input to the
+// input package.
+func (b *ReplayBackend) Init(title string, screenSize rl.Vector2, targetFps int32) {
+	b.Backend.Init(title, screenSize, targetFps)
+	input.SetInputSource(b)
+}
+
+// ShouldClose returns true once the recording is over, or if the wrapped
+// backend should close.
+func (b *ReplayBackend) ShouldClose() bool {
+	return b.source.IsDone() || b.Backend.ShouldClose()
+}
+
+// GetFrameTime returns the recorded duration of the current frame.
+func (b *ReplayBackend) GetFrameTime() float32 {
+	return b.source.GetFrameTime()
+}
+
+// EndFrame completes the frame on the wrapped backend and advances the
+// replay.
+func (b *ReplayBackend) EndFrame() {
+	b.Backend.EndFrame()
+	b.source.NextFrame()
+}
+
+// PollInputEvents returns the recorded events of the current frame.
+func (b *ReplayBackend) PollInputEvents() []*input_event.InputEvent {
+	return b.source.PollInputEvents()
+}
@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	input_event "gorl/fw/core/input/input_event"
+	input "gorl/fw/core/input/input_handling"
+	"gorl/fw/core/math"
+	"gorl/fw/core/render"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// movingEntity moves along the move vector, scaled by the frame time.
+type movingEntity struct {
+	*entities.Entity
+}
+
+func (ent *movingEntity) OnInputEvent(event *input_event.InputEvent) bool {
+	if event.Action == input_event.ActionMove {
+		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.Vector2Scale(event.Axis, 100*GetFrameTime())))
+	}
+	return true
+}
+
+// unevenBackend is a headless backend whose frames take different times.
+type unevenBackend struct {
+	*HeadlessBackend
+	frameTimes []float32
+}
+
+func (b *unevenBackend) GetFrameTime() float32 {
+	return b.frameTimes[b.GetFrameCount()%len(b.frameTimes)]
+}
+
+// runFrames runs the main loop for the given number of frames, or until the
+// backend should close, and returns the final position of a moving entity.
+func runFrames(b Backend, frames int, queue func(frame int)) rl.Vector2 {
+	Use(b)
+	b.Init("test", rl.NewVector2(320, 180), 60)
+	defer b.Deinit()
+	gem.Init()
+	defer gem.Deinit()
+
+	cam := render.NewCamera(rl.Vector2Zero(), rl.Vector2Zero(), rl.NewVector2(320, 180), rl.Vector2Zero(), math.Flag0)
+	defer cam.Destroy()
+
+	ent := &movingEntity{Entity: entities.NewEntity("moving", rl.Vector2Zero(), 0, rl.Vector2One())}
+	gem.Append(gem.GetRoot(), ent)
+
+	for frame := 0; frame < frames && !b.ShouldClose(); frame++ {
+		queue(frame)
+		b.BeginFrame()
+		drawables, processed := gem.Traverse()
+		drawn := render.Draw(drawables)
+		input.HandleInputEvents(processed, drawn)
+		b.EndFrame()
+	}
+	return ent.GetPosition()
+}
+
+// TestReplayReproducesRun records a run with uneven frame times, and checks
+// that replaying it from a file moves the entity to the same position.
+func TestReplayReproducesRun(t *testing.T) {
+	headless := NewHeadlessBackend(0)
+	uneven := &unevenBackend{HeadlessBackend: headless, frameTimes: []float32{1.0 / 60, 1.0 / 30, 1.0 / 144}}
+	path := filepath.Join(t.TempDir(), "run.replay")
+	recorder := NewRecordingBackend(uneven, path)
+
+	recorded := runFrames(recorder, 20, func(frame int) {
+		if frame%3 != 0 {
+			headless.Queue(input_event.NewInputEvent(input_event.ActionMoveRight, rl.Vector2{}))
+		}
+		if frame > 10 {
+			headless.Queue(input_event.NewInputEvent(input_event.ActionMoveDown, rl.Vector2{}))
+		}
+	})
+	if recorded == rl.Vector2Zero() {
+		t.Fatal("expected the entity to move while recording")
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	recording, err := input.LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recording.FrameCount() != 20 {
+		t.Fatalf("expected 20 recorded frames, got %d", recording.FrameCount())
+	}
+
+	replay := NewReplayBackend(NewHeadlessBackend(1), recording)
+	replayed := runFrames(replay, 100, func(int) {})
+	if replayed != recorded {
+		t.Errorf("expected the replay to end at %v, got %v", recorded, replayed)
+	}
+	if !replay.ShouldClose() {
+		t.Error("expected the replay backend to close after the recording")
+	}
+}
+
+// TestWindowedBackendsCaptureTriggers checks that rebinding works with the
+// raylib input source, also while recording.
+func TestWindowedBackendsCaptureTriggers(t *testing.T) {
+	raylib := NewRaylibBackend()
+	backends := map[string]Backend{
+		"raylib":    raylib,
+		"recording": NewRecordingBackend(raylib, filepath.Join(t.TempDir(), "run.json")),
+	}
+	for name, b := range backends {
+		if _, ok := b.(input.TriggerCapturer); !ok {
+			t.Errorf("%v backend can't capture triggers", name)
+		}
+	}
+	if _, ok := raylib.InputSource.(input.TriggerCapturer); !ok {
+		t.Errorf("raylib input source can't capture triggers")
+	}
+}
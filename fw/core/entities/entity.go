@@ -28,6 +28,23 @@ type Entity struct {
 	// Cameras can selectively render entities based on their layer flags.
 	// (Layer flags are not automatically inherited by children.)
 	layerFlags math.BitFlag
+
+	// tags are free form labels such as "enemy" or "player", used to find
+	// entities in the gem without holding on to pointers.
+	tags []string
+
+	// processPriority determines the order in which entities are updated.
+	// Lower values are updated first, entities with the same priority are
+	// updated in tree order.
+	processPriority int32
+
+	// pauseMode determines if the entity is processed while the game is
+	// paused.
+	pauseMode PauseMode
+
+	// treeNode links the entity to its node in the gem graph. It is nil while
+	// the entity is not part of the graph.
+	treeNode TreeNode
 }
 
 // NewEntity creates a new base implementation of IEntity.
@@ -40,6 +57,7 @@ func NewEntity(name string, position rl.Vector2, rotation float32, scale rl.Vect
 		transform:  math.NewTransform2D(position, rotation, scale),
 		drawIndex:  0,
 		layerFlags: math.Flag0,
+		tags:       make([]string, 0),
 	}
 }
 
@@ -47,8 +65,13 @@ func (ent *Entity) Init()        {} // Should be overridden by the custom entity
 func (ent *Entity) Deinit()      {} // Should be overridden by the custom entity.
 func (ent *Entity) Update()      {} // Should be overridden by the custom entity.
 func (ent *Entity) FixedUpdate() {} // Should be overridden by the custom entity.
+func (ent *Entity) LateUpdate()  {} // May be overridden by the custom entity.
 func (ent *Entity) Draw()        {} // Should be overridden by the custom entity.
 
+func (ent *Entity) OnEnterTree() {} // May be overridden by the custom entity.
+func (ent *Entity) OnExitTree()  {} // May be overridden by the custom entity.
+func (ent *Entity) OnReady()     {} // May be overridden by the custom entity.
+
 // String returns the name of the entity.
 func (ent *Entity) String() string {
 	return ent.Name
@@ -82,6 +105,7 @@ func (ent *Entity) GetPosition() rl.Vector2 {
 // SetPosition sets the position of the entity.
 func (ent *Entity) SetPosition(newPosition rl.Vector2) {
 	ent.transform.SetPosition(newPosition)
+	ent.markTransformDirty()
 }
 
 // GetScale returns the scale of the entity.
@@ -92,6 +116,7 @@ func (ent *Entity) GetScale() rl.Vector2 {
 // SetScale sets the scale of the entity.
 func (ent *Entity) SetScale(newScale rl.Vector2) {
 	ent.transform.SetScale(newScale)
+	ent.markTransformDirty()
 }
 
 // GetRotation returns the rotation of the entity.
@@ -102,11 +127,13 @@ func (ent *Entity) GetRotation() float32 {
 // SetRotation sets the rotation of the entity.
 func (ent *Entity) SetRotation(newRotation float32) {
 	ent.transform.SetRotation(newRotation)
+	ent.markTransformDirty()
 }
 
-// GetTransform returns the transform of the entity.
-// Should generally not be modified directly.
-// Use SetPosition, SetScale and SetRotation instead.
+// GetTransform returns the transform of the entity. The pointer is for
+// reading only: changes made through it are not noticed, so the global
+// transforms of the entity and its children go stale. Use SetPosition,
+// SetScale, SetRotation or SetTransform instead.
 func (ent *Entity) GetTransform() *math.Transform2D {
 	return &ent.transform
 }
@@ -115,6 +142,49 @@ func (ent *Entity) GetTransform() *math.Transform2D {
 // This includes the position, rotation and scale.
 func (ent *Entity) SetTransform(newTransform math.Transform2D) {
 	ent.transform = newTransform
+	ent.markTransformDirty()
+}
+
+// GetGlobalTransform returns the transform of the entity relative to the
+// root of the gem graph, combining the transforms of all its parents.
+// Outside of the graph, this is the same as GetTransform.
+func (ent *Entity) GetGlobalTransform() math.Transform2D {
+	if ent.treeNode == nil {
+		return ent.transform
+	}
+	return math.NewTransform2DFromMatrix3(ent.treeNode.GetWorldMatrix())
+}
+
+// GetGlobalPosition returns the position of the entity relative to the root
+// of the gem graph. Outside of the graph, this is the same as GetPosition.
+func (ent *Entity) GetGlobalPosition() rl.Vector2 {
+	if ent.treeNode == nil {
+		return ent.GetPosition()
+	}
+	return ent.treeNode.GetWorldMatrix().MultiplyV(rl.Vector2Zero())
+}
+
+// SetGlobalPosition moves the entity to the given position relative to the
+// root of the gem graph, by changing its local position accordingly.
+// Outside of the graph, this is the same as SetPosition.
+func (ent *Entity) SetGlobalPosition(newPosition rl.Vector2) {
+	if ent.treeNode == nil {
+		ent.SetPosition(newPosition)
+		return
+	}
+	toLocal, ok := ent.treeNode.GetParentWorldMatrix().Invert()
+	if !ok {
+		return // a parent is scaled to zero, there is no position to move to.
+	}
+	ent.SetPosition(toLocal.MultiplyV(newPosition))
+}
+
+// markTransformDirty tells the gem that the cached global transforms of the
+// entity and its children are outdated.
+func (ent *Entity) markTransformDirty() {
+	if ent.treeNode != nil {
+		ent.treeNode.MarkTransformDirty()
+	}
 }
 
 // OnInputEvent is called when an input event is received.
@@ -136,11 +206,38 @@ func (ent *Entity) SetDrawIndex(index int32) {
 	ent.drawIndex = index
 }
 
+// GetProcessPriority returns the process priority of the entity.
+// Lower values are updated before higher values.
+func (ent *Entity) GetProcessPriority() int32 {
+	return ent.processPriority
+}
+
+// SetProcessPriority sets the process priority of the entity.
+// Lower values are updated before higher values.
+func (ent *Entity) SetProcessPriority(priority int32) {
+	ent.processPriority = priority
+}
+
+// GetPauseMode returns the pause mode of the entity.
+func (ent *Entity) GetPauseMode() PauseMode {
+	return ent.pauseMode
+}
+
+// SetPauseMode sets the pause mode of the entity.
+func (ent *Entity) SetPauseMode(mode PauseMode) {
+	ent.pauseMode = mode
+}
+
 // GetName returns the name of the entity.
 func (ent *Entity) GetName() string {
 	return ent.Name
 }
 
+// SetName sets the name of the entity.
+func (ent *Entity) SetName(name string) {
+	ent.Name = name
+}
+
 // GetLayerFlags returns the layer flags of the entity.
 func (ent *Entity) GetLayerFlags() math.BitFlag {
 	return ent.layerFlags
@@ -150,3 +247,51 @@ func (ent *Entity) GetLayerFlags() math.BitFlag {
 func (ent *Entity) SetLayerFlags(flags math.BitFlag) {
 	ent.layerFlags = flags
 }
+
+// AddTag adds a tag to the entity. Adding a tag twice has no effect.
+func (ent *Entity) AddTag(tag string) {
+	if ent.HasTag(tag) {
+		return
+	}
+	ent.tags = append(ent.tags, tag)
+	if ent.treeNode != nil {
+		ent.treeNode.TagChanged(tag, true)
+	}
+}
+
+// RemoveTag removes a tag from the entity.
+func (ent *Entity) RemoveTag(tag string) {
+	for i, t := range ent.tags {
+		if t == tag {
+			ent.tags = append(ent.tags[:i], ent.tags[i+1:]...)
+			if ent.treeNode != nil {
+				ent.treeNode.TagChanged(tag, false)
+			}
+			return
+		}
+	}
+}
+
+// HasTag returns true if the entity has the given tag.
+func (ent *Entity) HasTag(tag string) bool {
+	for _, t := range ent.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTags returns a copy of the tags of the entity.
+func (ent *Entity) GetTags() []string {
+	tags := make([]string, len(ent.tags))
+	copy(tags, ent.tags)
+	return tags
+}
+
+// SetTreeNode links the entity to its node in the gem graph, or unlinks it
+// when passed nil. This is called by the gem and should not be called by
+// custom entities.
+func (ent *Entity) SetTreeNode(node TreeNode) {
+	ent.treeNode = node
+}
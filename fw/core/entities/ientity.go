@@ -13,11 +13,26 @@ type IEntity interface {
 	Init()
 	Deinit()
 
+	// Tree callbacks, called by the gem when the entity is added to, moved
+	// within or removed from the graph. See gem.Append, gem.ReParent and
+	// gem.Remove for the order they are called in.
+	OnEnterTree()
+	OnExitTree()
+	OnReady()
+
 	// Per-frame methods
+	// Every frame, Update is called on all processed entities, then
+	// LateUpdate. FixedUpdate is called zero or more times per frame, once
+	// per fixed step.
 	Update()
 	FixedUpdate()
+	LateUpdate()
 	Draw()
 
+	// Processing
+	GetProcessPriority() int32
+	GetPauseMode() PauseMode
+
 	// Transform
 	GetPosition() rl.Vector2
 	SetPosition(new_position rl.Vector2)
@@ -26,6 +41,9 @@ type IEntity interface {
 	SetRotation(new_rotation float32)
 	GetRotation() float32
 	math.Transformable // provides GetTransform()
+	GetGlobalTransform() math.Transform2D
+	GetGlobalPosition() rl.Vector2
+	SetGlobalPosition(new_position rl.Vector2)
 
 	// OnInputEvent is called when an input event is received.
 	// The entity must decide if it should handle the event or not.
@@ -39,6 +57,32 @@ type IEntity interface {
 	IsVisible() bool
 	GetLayerFlags() math.BitFlag
 
+	// Tags
+	AddTag(tag string)
+	RemoveTag(tag string)
+	HasTag(tag string) bool
+	GetTags() []string
+
+	// Gem
+	SetTreeNode(node TreeNode)
+
 	// Other
 	GetName() string
 }
+
+// TreeNode is the link between an entity and its node in the gem graph. The
+// gem hands it to entities via SetTreeNode, so they can report changes and
+// read their global transform.
+type TreeNode interface {
+	// GetWorldMatrix returns the cached global transformation matrix of the
+	// entity.
+	GetWorldMatrix() math.Matrix3
+	// GetParentWorldMatrix returns the cached global transformation matrix
+	// of the entity's parent.
+	GetParentWorldMatrix() math.Matrix3
+	// MarkTransformDirty invalidates the cached global transforms of the
+	// entity and its children.
+	MarkTransformDirty()
+	// TagChanged updates the tag index of the gem.
+	TagChanged(tag string, added bool)
+}
@@ -0,0 +1,36 @@
+package entities
+
+// PauseMode determines whether an entity is processed (updated and sent
+// input) depending on the global pause state, see store.AppState.Paused.
+type PauseMode int32
+
+const (
+	// PauseModeInherit uses the pause mode of the parent. Entities directly
+	// below the root inherit PauseModePausable.
+	PauseModeInherit PauseMode = iota
+	// PauseModePausable processes the entity only while the game is not
+	// paused.
+	PauseModePausable
+	// PauseModeAlways processes the entity regardless of the pause state.
+	PauseModeAlways
+	// PauseModeWhenPaused processes the entity only while the game is
+	// paused, e.g. for pause menus.
+	PauseModeWhenPaused
+	// PauseModeDisabled never processes the entity. It is still drawn.
+	PauseModeDisabled
+)
+
+// ShouldProcess returns true if an entity with this (already resolved) pause
+// mode should be processed in the given pause state.
+func (m PauseMode) ShouldProcess(paused bool) bool {
+	switch m {
+	case PauseModeAlways:
+		return true
+	case PauseModeWhenPaused:
+		return paused
+	case PauseModeDisabled:
+		return false
+	default:
+		return !paused
+	}
+}
@@ -0,0 +1,75 @@
+package gem
+
+import (
+	"gorl/fw/core/entities"
+	"gorl/fw/core/logging"
+)
+
+// queueCommand queues a structural change to be applied by FlushCommands.
+func queueCommand(cmd func()) {
+	gemInstance.commands = append(gemInstance.commands, cmd)
+}
+
+// QueueFree marks the entity for removal from the graph. Unlike Remove, the
+// entity is never removed right away, even outside of a traversal, but at
+// the next call to FlushCommands. Marking an entity twice has no effect.
+//
+// This is the safe way for an entity to remove itself or others from within
+// Update, FixedUpdate or OnInputEvent.
+func QueueFree(entity entities.IEntity) {
+	if _, ok := gemInstance.nodeMap[entity]; !ok {
+		logging.Error("entity not found in graph, can't queue it for removal")
+		return
+	}
+	if gemInstance.queuedFree[entity] {
+		return
+	}
+	gemInstance.queuedFree[entity] = true
+	queueCommand(func() {
+		// the entity might have been removed together with an ancestor.
+		if _, ok := gemInstance.nodeMap[entity]; ok {
+			removeNow(entity)
+		}
+	})
+}
+
+// IsQueuedForDeletion returns true if QueueFree was called on the entity and
+// it has not been removed yet.
+func IsQueuedForDeletion(entity entities.IEntity) bool {
+	return gemInstance.queuedFree[entity]
+}
+
+// FlushCommands applies all queued structural changes in the order they were
+// made. Commands queued while flushing, e.g. by an entity's Init, are applied
+// as well.
+//
+// The game loop calls this once at the end of every frame, after drawing and
+// input handling, so entities never see the graph change mid-frame.
+func FlushCommands() {
+	if gemInstance.traversing > 0 {
+		logging.Warning("Can't flush commands during a traversal, they are flushed once it ends.")
+		return
+	}
+	for len(gemInstance.commands) > 0 {
+		cmd := gemInstance.commands[0]
+		gemInstance.commands = gemInstance.commands[1:]
+		cmd()
+	}
+}
+
+// IsTraversing returns true while a traversal runs, i.e. while structural
+// changes are deferred until the next FlushCommands.
+func IsTraversing() bool {
+	return gemInstance.traversing > 0
+}
+
+// beginTraversal defers structural changes until the matching endTraversal.
+func beginTraversal() {
+	gemInstance.traversing++
+}
+
+// endTraversal ends a traversal. Queued changes stay queued until the next
+// FlushCommands.
+func endTraversal() {
+	gemInstance.traversing--
+}
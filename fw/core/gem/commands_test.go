@@ -0,0 +1,92 @@
+package gem
+
+import (
+	"reflect"
+	"testing"
+
+	"gorl/fw/core/entities"
+)
+
+// lifecycleEntity records its lifecycle callbacks into a shared log and runs
+// onUpdate during Update.
+type lifecycleEntity struct {
+	*entities.Entity
+	log      *[]string
+	onInit   func()
+	onUpdate func()
+}
+
+func newLifecycleEntity(name string, log *[]string) *lifecycleEntity {
+	return &lifecycleEntity{Entity: newPlainEntity(name), log: log}
+}
+
+func (ent *lifecycleEntity) record(event string) {
+	*ent.log = append(*ent.log, ent.GetName()+":"+event)
+}
+
+func (ent *lifecycleEntity) Init() {
+	ent.record("init")
+	if ent.onInit != nil {
+		ent.onInit()
+	}
+}
+func (ent *lifecycleEntity) Deinit()      { ent.record("deinit") }
+func (ent *lifecycleEntity) OnEnterTree() { ent.record("enter") }
+func (ent *lifecycleEntity) OnExitTree()  { ent.record("exit") }
+func (ent *lifecycleEntity) OnReady()     { ent.record("ready") }
+func (ent *lifecycleEntity) Update() {
+	if ent.onUpdate != nil {
+		ent.onUpdate()
+	}
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	Init()
+	log := []string{}
+	parent := newLifecycleEntity("parent", &log)
+	child := newLifecycleEntity("child", &log)
+	parent.onInit = func() { Append(parent, child) }
+
+	Append(GetRoot(), parent)
+	Remove(parent)
+
+	expected := []string{
+		"parent:enter", "parent:init",
+		"child:enter", "child:init", "child:ready",
+		"parent:ready",
+		"parent:exit", "child:exit", "child:deinit", "parent:deinit",
+	}
+	if !reflect.DeepEqual(log, expected) {
+		t.Errorf("unexpected lifecycle order:\n got %v\nwant %v", log, expected)
+	}
+}
+
+func TestMutationsDuringTraversalAreDeferred(t *testing.T) {
+	Init()
+	log := []string{}
+	spawner := newLifecycleEntity("spawner", &log)
+	spawned := newLifecycleEntity("spawned", &log)
+	spawner.onUpdate = func() {
+		if !IsQueuedForDeletion(spawner) {
+			Append(GetRoot(), spawned)
+			QueueFree(spawner)
+		}
+	}
+	Append(GetRoot(), spawner)
+
+	Traverse()
+	if Find("spawned") != nil {
+		t.Errorf("expected the append to be deferred until FlushCommands")
+	}
+	if Find("spawner") == nil {
+		t.Errorf("expected the removal to be deferred until FlushCommands")
+	}
+
+	FlushCommands()
+	if Find("spawned") != spawned {
+		t.Errorf("expected the spawned entity to be in the graph after flushing")
+	}
+	if Find("spawner") != nil || IsQueuedForDeletion(spawner) {
+		t.Errorf("expected the spawner to be removed after flushing")
+	}
+}
@@ -1,8 +1,11 @@
 package gem
 
 import (
+	"reflect"
+
 	"gorl/fw/core/entities"
 	"gorl/fw/core/logging"
+	"gorl/fw/core/math"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -11,6 +14,18 @@ import (
 type gem struct {
 	root    *gemNode
 	nodeMap map[entities.IEntity]*gemNode
+
+	// indexes used by the query functions. Nodes are kept in the order they
+	// were added to the graph.
+	tagIndex  map[string][]*gemNode
+	typeIndex map[reflect.Type][]*gemNode
+
+	// traversing is greater than zero while a traversal is running. During
+	// that time, structural changes are queued in commands instead of being
+	// applied right away.
+	traversing int
+	commands   []func()
+	queuedFree map[entities.IEntity]bool
 }
 
 // gemNode represents a node in the Gem graph.
@@ -19,6 +34,16 @@ type gemNode struct {
 	entity   entities.IEntity
 	parent   *gemNode
 	children []*gemNode
+
+	// worldMatrix caches the global transformation matrix of the entity. It
+	// is recalculated lazily once worldDirty is set. If a node is dirty, all
+	// of its descendants are dirty as well.
+	worldMatrix math.Matrix3
+	worldDirty  bool
+
+	// frozen stops processing the subtree, regardless of the pause modes in
+	// it, see SetFrozen.
+	frozen bool
 }
 
 const DefaultLayer = 0
@@ -26,20 +51,30 @@ const DefaultLayer = 0
 // gemInstance is the global Gem graph.
 var gemInstance *gem
 
+// removeHooks are called for every entity removed from the graph. They are
+// kept outside of gemInstance, so they survive Init.
+var removeHooks []func(entities.IEntity)
+
 // Init initializes the global Gem graph.
 // This should be called once at the start of the program.
 func Init() {
 	rootEntity := entities.NewEntity("root", rl.Vector2Zero(), 0, rl.Vector2One())
 	gemInstance = &gem{
 		root: &gemNode{
-			entity:   rootEntity,
-			parent:   nil,
-			children: make([]*gemNode, 0),
+			entity:     rootEntity,
+			parent:     nil,
+			children:   make([]*gemNode, 0),
+			worldDirty: true,
 		},
-		nodeMap: make(map[entities.IEntity]*gemNode),
+		nodeMap:    make(map[entities.IEntity]*gemNode),
+		tagIndex:   make(map[string][]*gemNode),
+		typeIndex:  make(map[reflect.Type][]*gemNode),
+		commands:   make([]func(), 0),
+		queuedFree: make(map[entities.IEntity]bool),
 	}
 	// self-map the root entity
 	gemInstance.nodeMap[gemInstance.root.entity] = gemInstance.root
+	rootEntity.SetTreeNode(gemInstance.root)
 }
 
 // GetRoot returns the root entity of the Gem graph.
@@ -48,61 +83,126 @@ func GetRoot() entities.IEntity {
 }
 
 // Deinit deinitializes the global Gem graph, calling Deinit on all entities.
+// Pending commands are applied first.
 func Deinit() {
-	// FIXME: causes segfault, fix
-	Remove(gemInstance.root.entity)
+	FlushCommands()
+	removeNow(gemInstance.root.entity)
 }
 
 // Append adds a entities.IEntity to the Gem graph, as a child of the given parent.
+//
+// The entity's lifecycle methods are called in this order: OnEnterTree, Init,
+// OnReady. Entities appended during Init are ready before their parent.
+//
+// If called during a traversal (e.g. from an entity's Update), the change is
+// deferred until the next FlushCommands.
 func Append(parent, entity entities.IEntity) {
+	if gemInstance.traversing > 0 {
+		queueCommand(func() { appendNow(parent, entity) })
+		return
+	}
+	appendNow(parent, entity)
+}
+
+func appendNow(parent, entity entities.IEntity) {
 	parentNode, ok := gemInstance.nodeMap[parent]
 	if !ok {
 		logging.Error("Parent not found in graph, can't add child")
 		return
 	}
+	if _, exists := gemInstance.nodeMap[entity]; exists {
+		logging.Error("entity already in graph, can't add it twice")
+		return
+	}
 
 	node := &gemNode{
-		entity:   entity,
-		parent:   parentNode,
-		children: make([]*gemNode, 0),
+		entity:     entity,
+		parent:     parentNode,
+		children:   make([]*gemNode, 0),
+		worldDirty: true,
 	}
 	parentNode.children = append(parentNode.children, node)
 	gemInstance.nodeMap[entity] = node
+	indexNode(node)
 
+	entity.OnEnterTree()
 	entity.Init()
+	entity.OnReady()
 }
 
 // Remove removes a entities.IEntity from the graph.
 // All children of the removed entity are also removed.
+//
+// OnExitTree is called top-down, parents before their children, while
+// Deinit is called bottom-up, once all children have been removed.
+//
+// If called during a traversal, the change is deferred until the next
+// FlushCommands.
 func Remove(entity entities.IEntity) {
+	if gemInstance.traversing > 0 {
+		queueCommand(func() { removeNow(entity) })
+		return
+	}
+	removeNow(entity)
+}
+
+func removeNow(entity entities.IEntity) {
 	node, ok := gemInstance.nodeMap[entity]
 	if !ok {
 		logging.Error("entity not found in graph, can't remove")
 		return
 	}
 
+	entity.OnExitTree()
+
 	// recursively remove all children. This will call Deinit on all children.
-	for _, child := range node.children {
-		Remove(child.entity)
+	// we iterate over a copy, since each removal shrinks node.children.
+	children := make([]*gemNode, len(node.children))
+	copy(children, node.children)
+	for _, child := range children {
+		removeNow(child.entity)
 	}
 
-	// remove the node from the parent's children
-	parent := node.parent
-	for i, child := range parent.children {
-		if child == node {
-			parent.children = append(parent.children[:i], parent.children[i+1:]...)
-			break
-		}
+	// remove the node from the parent's children. only the root has no parent.
+	if node.parent != nil {
+		detachNode(node)
 	}
 
-	// remove the node from the node map
+	// remove the node from the node map and indexes
 	delete(gemInstance.nodeMap, entity)
+	delete(gemInstance.queuedFree, entity)
+	unindexNode(node)
 
 	entity.Deinit()
+	for _, hook := range removeHooks {
+		hook(entity)
+	}
+}
+
+// OnRemove registers a function that is called for every entity removed from
+// the graph, right after its Deinit. Modules use this to drop what they keep
+// per entity, e.g. signal connections.
+func OnRemove(hook func(entity entities.IEntity)) {
+	removeHooks = append(removeHooks, hook)
 }
 
 // ReParent changes the parent of a entities.IEntity.
+//
+// The moved subtree exits the tree and enters it again under its new parent,
+// so OnExitTree and OnEnterTree are called for every entity in it. Init and
+// OnReady are not called again.
+//
+// If called during a traversal, the change is deferred until the next
+// FlushCommands.
 func ReParent(entity, newParent entities.IEntity) {
+	if gemInstance.traversing > 0 {
+		queueCommand(func() { reParentNow(entity, newParent) })
+		return
+	}
+	reParentNow(entity, newParent)
+}
+
+func reParentNow(entity, newParent entities.IEntity) {
 	node, ok := gemInstance.nodeMap[entity]
 	if !ok {
 		logging.Error("entity not found in graph, can't reparent")
@@ -115,18 +215,40 @@ func ReParent(entity, newParent entities.IEntity) {
 		return
 	}
 
-	// remove the node from the old parent's children
-	oldParent := node.parent
-	for i, child := range oldParent.children {
+	if isInSubtree(newParentNode, node) {
+		logging.Error("New parent is part of the entity's subtree, can't reparent")
+		return
+	}
+
+	walkSubtree(node, func(n *gemNode) { n.entity.OnExitTree() })
+
+	// move the node from the old parent's children to the new parent's
+	detachNode(node)
+	newParentNode.children = append(newParentNode.children, node)
+	node.parent = newParentNode
+	node.MarkTransformDirty()
+
+	walkSubtree(node, func(n *gemNode) { n.entity.OnEnterTree() })
+}
+
+// detachNode removes the node from its parent's children.
+func detachNode(node *gemNode) {
+	parent := node.parent
+	for i, child := range parent.children {
 		if child == node {
-			oldParent.children = append(oldParent.children[:i], oldParent.children[i+1:]...)
+			parent.children = append(parent.children[:i], parent.children[i+1:]...)
 			break
 		}
 	}
+}
 
-	// add the node to the new parent's children
-	newParentNode.children = append(newParentNode.children, node)
-	node.parent = newParentNode
+// walkSubtree calls fn for the node and all its descendants, parents before
+// their children.
+func walkSubtree(node *gemNode, fn func(*gemNode)) {
+	fn(node)
+	for _, child := range node.children {
+		walkSubtree(child, fn)
+	}
 }
 
 // GetChildren returns the children of a entities.IEntity.
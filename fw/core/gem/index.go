@@ -0,0 +1,44 @@
+package gem
+
+import (
+	"reflect"
+)
+
+// indexNode adds a node to the tag and type indexes and links the node to
+// its entity, so tag changes are reported back.
+func indexNode(node *gemNode) {
+	t := reflect.TypeOf(node.entity)
+	gemInstance.typeIndex[t] = append(gemInstance.typeIndex[t], node)
+
+	for _, tag := range node.entity.GetTags() {
+		gemInstance.tagIndex[tag] = append(gemInstance.tagIndex[tag], node)
+	}
+	node.entity.SetTreeNode(node)
+}
+
+// unindexNode removes a node from the tag and type indexes and unlinks it
+// from its entity.
+func unindexNode(node *gemNode) {
+	node.entity.SetTreeNode(nil)
+	for _, tag := range node.entity.GetTags() {
+		removeFromIndex(gemInstance.tagIndex, tag, node)
+	}
+	removeFromIndex(gemInstance.typeIndex, reflect.TypeOf(node.entity), node)
+}
+
+// removeFromIndex removes a node from the index entry of the given key,
+// deleting the entry once it is empty.
+func removeFromIndex[K comparable](index map[K][]*gemNode, key K, node *gemNode) {
+	nodes := index[key]
+	for i, n := range nodes {
+		if n == node {
+			nodes = append(nodes[:i], nodes[i+1:]...)
+			break
+		}
+	}
+	if len(nodes) == 0 {
+		delete(index, key)
+		return
+	}
+	index[key] = nodes
+}
@@ -0,0 +1,177 @@
+package gem
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	"gorl/fw/core/assets"
+	"gorl/fw/core/entities"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PrefabData is the content of a prefab file, describing a single entity
+// subtree that can be instanced many times.
+type PrefabData struct {
+	Version int        `json:"version"`
+	Entity  EntityData `json:"entity"`
+}
+
+// Overrides changes a prefab instance. Nil fields keep the value stored in
+// the prefab, properties are merged into the prefab's properties.
+type Overrides struct {
+	Name       *string        `json:"name,omitempty"`
+	Position   *rl.Vector2    `json:"position,omitempty"`
+	Rotation   *float32       `json:"rotation,omitempty"`
+	Scale      *rl.Vector2    `json:"scale,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+
+	// Children overrides entities inside the prefab, keyed by their path
+	// relative to the prefab root, e.g. "Body/Sprite".
+	Children map[string]Overrides `json:"children,omitempty"`
+}
+
+// prefabCache holds the decoded prefab files, keyed by path.
+var prefabCache = make(map[string]EntityData)
+
+// Instantiate creates a new instance of the prefab stored at path and
+// appends it below parent. The overrides may be nil.
+//
+// Prefab files are read once through the asset cache and kept decoded, see
+// ClearPrefabCache.
+func Instantiate(path string, parent entities.IEntity, overrides *Overrides) (entities.IEntity, error) {
+	data := EntityData{Prefab: path, Overrides: overrides}
+	return SpawnEntity(parent, data)
+}
+
+// ClearPrefabCache forgets all cached prefab files, so changes on disk are
+// picked up by the next Instantiate.
+func ClearPrefabCache() {
+	for path := range prefabCache {
+		assets.Unload(path)
+	}
+	clear(prefabCache)
+}
+
+// readPrefab returns the decoded prefab file at path.
+func readPrefab(path string) (EntityData, error) {
+	if data, ok := prefabCache[path]; ok {
+		return data, nil
+	}
+
+	content, err := assets.ReadFile(path)
+	if err != nil {
+		return EntityData{}, err
+	}
+	var prefab PrefabData
+	if err := json.Unmarshal(content, &prefab); err != nil {
+		return EntityData{}, fmt.Errorf("%v: %w", path, err)
+	}
+	if prefab.Version > TreeFormatVersion {
+		return EntityData{}, fmt.Errorf("%v: unsupported format version %v", path, prefab.Version)
+	}
+
+	prefabCache[path] = prefab.Entity
+	return prefab.Entity, nil
+}
+
+// expandPrefabs returns a copy of data in which all prefab references,
+// including nested ones, are replaced by the prefab content with the
+// overrides applied. visiting holds the prefabs currently being expanded, to
+// detect prefabs that contain themselves.
+func expandPrefabs(data EntityData, visiting []string) (EntityData, error) {
+	// copy the children, so the cached prefab data is never modified.
+	children := make([]EntityData, len(data.Children))
+	for i, child := range data.Children {
+		var err error
+		if children[i], err = expandPrefabs(child, visiting); err != nil {
+			return EntityData{}, err
+		}
+	}
+	if data.Prefab == "" {
+		data.Children = children
+		return data, nil
+	}
+
+	for _, path := range visiting {
+		if path == data.Prefab {
+			return EntityData{}, fmt.Errorf("prefab %v contains itself", path)
+		}
+	}
+	prefab, err := readPrefab(data.Prefab)
+	if err != nil {
+		return EntityData{}, err
+	}
+	instance, err := expandPrefabs(prefab, append(visiting, data.Prefab))
+	if err != nil {
+		return EntityData{}, err
+	}
+
+	// children listed next to the reference are added to the instance.
+	instance.Children = append(instance.Children, children...)
+	if data.Overrides != nil {
+		if err := applyOverrides(&instance, *data.Overrides); err != nil {
+			return EntityData{}, fmt.Errorf("prefab %v: %w", data.Prefab, err)
+		}
+	}
+	return instance, nil
+}
+
+// applyOverrides applies the overrides to an expanded entity description.
+func applyOverrides(data *EntityData, overrides Overrides) error {
+	if overrides.Name != nil {
+		data.Name = *overrides.Name
+	}
+	if overrides.Position != nil {
+		data.Position = *overrides.Position
+	}
+	if overrides.Rotation != nil {
+		data.Rotation = *overrides.Rotation
+	}
+	if overrides.Scale != nil {
+		data.Scale = *overrides.Scale
+	}
+	if len(overrides.Properties) > 0 {
+		properties := maps.Clone(data.Properties)
+		if properties == nil {
+			properties = make(map[string]any, len(overrides.Properties))
+		}
+		maps.Copy(properties, overrides.Properties)
+		data.Properties = properties
+	}
+
+	for path, childOverrides := range overrides.Children {
+		child := findChildData(data, path)
+		if child == nil {
+			return fmt.Errorf("no child at %q to override", path)
+		}
+		if err := applyOverrides(child, childOverrides); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findChildData returns the description of the entity at path below data,
+// following the rules of FindChild.
+func findChildData(data *EntityData, path string) *EntityData {
+	for _, name := range strings.Split(path, PathSeparator) {
+		if name == "" {
+			continue
+		}
+		var next *EntityData
+		for i := range data.Children {
+			if data.Children[i].Name == name {
+				next = &data.Children[i]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		data = next
+	}
+	return data
+}
@@ -0,0 +1,75 @@
+package gem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %v: %v", path, err)
+	}
+}
+
+func TestInstantiateWithOverrides(t *testing.T) {
+	Init()
+	registry = newTypeRegistry()
+	RegisterType("propEntity", newPropEntity)
+	ClearPrefabCache()
+
+	dir := t.TempDir()
+	gunPath := filepath.Join(dir, "gun.json")
+	enemyPath := filepath.Join(dir, "enemy.json")
+	writeTestFile(t, gunPath, `{"version": 1, "entity":
+		{"type": "propEntity", "name": "Gun", "properties": {"speed": 5}}}`)
+	writeTestFile(t, enemyPath, `{"version": 1, "entity":
+		{"type": "propEntity", "name": "Enemy", "tags": ["enemy"], "children": [
+			{"prefab": "`+gunPath+`"}
+		]}}`)
+
+	name := "Boss"
+	position := rl.NewVector2(3, 4)
+	boss, err := Instantiate(enemyPath, GetRoot(), &Overrides{
+		Name:     &name,
+		Position: &position,
+		Children: map[string]Overrides{
+			"Gun": {Properties: map[string]any{"speed": 9}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to instantiate: %v", err)
+	}
+	if _, err := Instantiate(enemyPath, GetRoot(), nil); err != nil {
+		t.Fatalf("failed to instantiate: %v", err)
+	}
+
+	if Find("Boss") != boss || boss.GetPosition() != position {
+		t.Errorf("expected the name and position to be overridden")
+	}
+	if gun := Find("Boss/Gun").(*propEntity); gun.Speed != 9 {
+		t.Errorf("expected the nested prefab to be overridden, got speed %v", gun.Speed)
+	}
+	if gun := Find("Enemy/Gun").(*propEntity); gun.Speed != 5 {
+		t.Errorf("expected the overrides not to leak into other instances, got speed %v", gun.Speed)
+	}
+	if len(FindByTag("enemy")) != 2 {
+		t.Errorf("expected two enemies")
+	}
+}
+
+func TestInstantiateRejectsRecursivePrefabs(t *testing.T) {
+	Init()
+	registry = newTypeRegistry()
+	ClearPrefabCache()
+
+	path := filepath.Join(t.TempDir(), "loop.json")
+	writeTestFile(t, path, `{"version": 1, "entity": {"children": [{"prefab": "`+path+`"}]}}`)
+
+	if _, err := Instantiate(path, GetRoot(), nil); err == nil {
+		t.Errorf("expected an error for a prefab containing itself")
+	}
+}
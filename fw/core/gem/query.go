@@ -0,0 +1,125 @@
+package gem
+
+import (
+	"reflect"
+	"strings"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/logging"
+)
+
+// PathSeparator separates entity names in a path.
+const PathSeparator = "/"
+
+// Find returns the entity at the given path, relative to the root of the Gem
+// graph. A path is a list of entity names separated by PathSeparator, e.g.
+// "Angles/CameraEntity". If several siblings share a name, the one added
+// first is returned. Returns nil if no entity is found.
+func Find(path string) entities.IEntity {
+	return FindChild(gemInstance.root.entity, path)
+}
+
+// FindChild returns the entity at the given path, relative to the given
+// parent. See Find for the path format. Returns nil if no entity is found.
+func FindChild(parent entities.IEntity, path string) entities.IEntity {
+	node, ok := gemInstance.nodeMap[parent]
+	if !ok {
+		logging.Error("Parent not found in graph, can't find child")
+		return nil
+	}
+
+	for _, name := range strings.Split(path, PathSeparator) {
+		if name == "" {
+			continue // allows leading, trailing and double separators
+		}
+		var next *gemNode
+		for _, child := range node.children {
+			if child.entity.GetName() == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node.entity
+}
+
+// GetPath returns the path of the entity relative to the root of the Gem
+// graph, such that Find(GetPath(entity)) returns the entity again, unless a
+// sibling with the same name was added before it.
+func GetPath(entity entities.IEntity) string {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		logging.Error("entity not found in graph, can't get path")
+		return ""
+	}
+
+	names := []string{}
+	for ; node != gemInstance.root && node != nil; node = node.parent {
+		names = append(names, node.entity.GetName())
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, PathSeparator)
+}
+
+// FindByTag returns all entities in the Gem graph that have the given tag,
+// in the order they were added to the graph.
+func FindByTag(tag string) []entities.IEntity {
+	nodes := gemInstance.tagIndex[tag]
+	found := make([]entities.IEntity, len(nodes))
+	for i, node := range nodes {
+		found[i] = node.entity
+	}
+	return found
+}
+
+// Query returns all entities of type T in the subtree of root, including
+// root itself. T may be a concrete entity type such as *CameraEntity, or an
+// interface that the entities must implement.
+//
+// Concrete types are looked up in an index, interfaces require walking the
+// subtree.
+func Query[T any](root entities.IEntity) []T {
+	rootNode, ok := gemInstance.nodeMap[root]
+	if !ok {
+		logging.Error("Root not found in graph, can't query")
+		return nil
+	}
+
+	found := make([]T, 0)
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	if t.Kind() != reflect.Interface {
+		for _, node := range gemInstance.typeIndex[t] {
+			if isInSubtree(node, rootNode) {
+				found = append(found, node.entity.(T))
+			}
+		}
+		return found
+	}
+
+	nodes := []*gemNode{rootNode}
+	for len(nodes) > 0 {
+		node := nodes[0]
+		nodes = append(nodes[1:], node.children...)
+		if match, ok := node.entity.(T); ok {
+			found = append(found, match)
+		}
+	}
+	return found
+}
+
+// isInSubtree returns true if node is root or one of its descendants.
+func isInSubtree(node, root *gemNode) bool {
+	for ; node != nil; node = node.parent {
+		if node == root {
+			return true
+		}
+	}
+	return false
+}
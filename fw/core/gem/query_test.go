@@ -0,0 +1,98 @@
+package gem
+
+import (
+	"testing"
+
+	"gorl/fw/core/entities"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type enemyEntity struct {
+	*entities.Entity
+}
+
+func newEnemyEntity(name string) *enemyEntity {
+	return &enemyEntity{Entity: entities.NewEntity(name, rl.Vector2Zero(), 0, rl.Vector2One())}
+}
+
+func newPlainEntity(name string) *entities.Entity {
+	return entities.NewEntity(name, rl.Vector2Zero(), 0, rl.Vector2One())
+}
+
+func TestFindAndGetPath(t *testing.T) {
+	Init()
+	scene := newPlainEntity("Angles")
+	Append(GetRoot(), scene)
+	cam := newPlainEntity("CameraEntity")
+	Append(scene, cam)
+
+	if found := Find("Angles/CameraEntity"); found != cam {
+		t.Errorf("expected to find the camera, got %v", found)
+	}
+	if found := Find("Angles/Missing"); found != nil {
+		t.Errorf("expected nil for a missing entity, got %v", found)
+	}
+	if found := FindChild(scene, "CameraEntity"); found != cam {
+		t.Errorf("expected to find the camera relative to the scene, got %v", found)
+	}
+	if path := GetPath(cam); path != "Angles/CameraEntity" {
+		t.Errorf("expected path Angles/CameraEntity, got %s", path)
+	}
+
+	other := newPlainEntity("Other")
+	Append(GetRoot(), other)
+	ReParent(cam, other)
+	if found := Find("Other/CameraEntity"); found != cam {
+		t.Errorf("expected to find the camera after reparenting, got %v", found)
+	}
+}
+
+func TestFindByTag(t *testing.T) {
+	Init()
+	a := newEnemyEntity("a")
+	a.AddTag("enemy")
+	Append(GetRoot(), a)
+	b := newEnemyEntity("b")
+	Append(GetRoot(), b)
+	b.AddTag("enemy") // tags added after Append must be indexed too
+
+	if found := FindByTag("enemy"); len(found) != 2 || found[0] != a || found[1] != b {
+		t.Errorf("expected [a b], got %v", found)
+	}
+
+	a.RemoveTag("enemy")
+	if found := FindByTag("enemy"); len(found) != 1 || found[0] != b {
+		t.Errorf("expected [b] after removing the tag from a, got %v", found)
+	}
+
+	Remove(b)
+	if found := FindByTag("enemy"); len(found) != 0 {
+		t.Errorf("expected no tagged entities after removing b, got %v", found)
+	}
+	b.AddTag("boss") // removed entities must no longer touch the index
+	if found := FindByTag("boss"); len(found) != 0 {
+		t.Errorf("expected removed entities not to be indexed, got %v", found)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	Init()
+	group := newPlainEntity("group")
+	Append(GetRoot(), group)
+	inside := newEnemyEntity("inside")
+	Append(group, inside)
+	outside := newEnemyEntity("outside")
+	Append(GetRoot(), outside)
+
+	if found := Query[*enemyEntity](GetRoot()); len(found) != 2 {
+		t.Errorf("expected 2 enemies in the whole graph, got %v", found)
+	}
+	if found := Query[*enemyEntity](group); len(found) != 1 || found[0] != inside {
+		t.Errorf("expected only the enemy inside the group, got %v", found)
+	}
+	// interfaces match every entity implementing them, root included.
+	if found := Query[entities.IEntity](group); len(found) != 2 {
+		t.Errorf("expected the group and its child, got %v", found)
+	}
+}
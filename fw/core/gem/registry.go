@@ -0,0 +1,75 @@
+package gem
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/logging"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Constructor creates a new instance of a registered entity type. It is
+// called when an entity tree is loaded from a data file. The base properties
+// and exported fields stored in the file are applied afterwards.
+type Constructor func() entities.IEntity
+
+// typeRegistry maps the names used in data files to entity constructors, and
+// the entity types back to their names for saving.
+type typeRegistry struct {
+	constructors map[string]Constructor
+	names        map[reflect.Type]string
+}
+
+// BaseEntityType is the registered name of the plain entities.Entity, as
+// used e.g. for scene roots.
+const BaseEntityType = "Entity"
+
+var registry = newTypeRegistry()
+
+func newTypeRegistry() *typeRegistry {
+	r := &typeRegistry{
+		constructors: make(map[string]Constructor),
+		names:        make(map[reflect.Type]string),
+	}
+	registerType(r, BaseEntityType, func() *entities.Entity {
+		return entities.NewEntity(BaseEntityType, rl.Vector2Zero(), 0, rl.Vector2One())
+	})
+	return r
+}
+
+// RegisterType registers an entity constructor under the given name, so
+// entities of that type can be saved to and loaded from data files.
+// Registering the same name twice is a fatal error.
+//
+//	gem.RegisterType("CameraEntity", func() *CameraEntity { ... })
+func RegisterType[T entities.IEntity](name string, constructor func() T) {
+	registerType(registry, name, constructor)
+}
+
+func registerType[T entities.IEntity](r *typeRegistry, name string, constructor func() T) {
+	if _, exists := r.constructors[name]; exists {
+		logging.Fatal("Entity type \"%v\" is already registered.", name)
+	}
+	r.constructors[name] = func() entities.IEntity { return constructor() }
+	r.names[reflect.TypeOf((*T)(nil)).Elem()] = name
+}
+
+// newEntityOfType creates a new entity of the registered type.
+func (r *typeRegistry) newEntityOfType(name string) (entities.IEntity, error) {
+	constructor, ok := r.constructors[name]
+	if !ok {
+		return nil, fmt.Errorf("entity type %q is not registered", name)
+	}
+	return constructor(), nil
+}
+
+// typeName returns the registered name of the entity's type.
+func (r *typeRegistry) typeName(entity entities.IEntity) (string, error) {
+	name, ok := r.names[reflect.TypeOf(entity)]
+	if !ok {
+		return "", fmt.Errorf("entity type %T is not registered", entity)
+	}
+	return name, nil
+}
@@ -0,0 +1,348 @@
+package gem
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"gorl/fw/core/assets"
+	"gorl/fw/core/entities"
+	"gorl/fw/core/math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TreeFormatVersion is the version of the data file format written by
+// SaveTree.
+const TreeFormatVersion = 1
+
+// PropertyTag is the struct tag that marks exported fields of a custom entity
+// to be stored in data files, e.g.
+//
+//	Radius float32 `prop:"radius"`
+const PropertyTag = "prop"
+
+// TreeData is the content of a data file, describing a list of entity
+// subtrees that are loaded below a common parent.
+type TreeData struct {
+	Version  int          `json:"version"`
+	Entities []EntityData `json:"entities"`
+}
+
+// EntityData describes a single entity and its children.
+type EntityData struct {
+	// Type is the name the entity's type was registered with, see
+	// RegisterType.
+	Type            string             `json:"type"`
+	Name            string             `json:"name"`
+	Position        rl.Vector2         `json:"position"`
+	Rotation        float32            `json:"rotation"`
+	Scale           rl.Vector2         `json:"scale"`
+	DrawIndex       int32              `json:"drawIndex"`
+	LayerFlags      math.BitFlag       `json:"layerFlags"`
+	Enabled         bool               `json:"enabled"`
+	Visible         bool               `json:"visible"`
+	ProcessPriority int32              `json:"processPriority,omitempty"`
+	PauseMode       entities.PauseMode `json:"pauseMode,omitempty"`
+	Tags            []string           `json:"tags,omitempty"`
+	Properties      map[string]any     `json:"properties,omitempty"`
+	Children        []EntityData       `json:"children,omitempty"`
+
+	// Prefab is the path of a prefab file to instance instead. All other
+	// fields except Children are ignored, use Overrides to change the
+	// instance. Children are added to the ones of the prefab.
+	Prefab    string     `json:"prefab,omitempty"`
+	Overrides *Overrides `json:"overrides,omitempty"`
+}
+
+// UnmarshalJSON decodes the entity data, using the defaults of
+// entities.NewEntity for fields that are missing in the file.
+func (data *EntityData) UnmarshalJSON(b []byte) error {
+	// the alias type has no methods, so this does not recurse.
+	type entityData EntityData
+	decoded := entityData{
+		Type:       BaseEntityType,
+		Scale:      rl.Vector2One(),
+		LayerFlags: math.Flag0,
+		Enabled:    true,
+		Visible:    true,
+	}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	*data = EntityData(decoded)
+	return nil
+}
+
+// entitySetters are setters of entities.Entity that are not part of IEntity.
+// Every custom entity gets them by embedding entities.Entity.
+type entitySetters interface {
+	SetName(name string)
+	SetEnabled(enabled bool)
+	SetVisible(visible bool)
+	SetLayerFlags(flags math.BitFlag)
+	SetProcessPriority(priority int32)
+	SetPauseMode(mode entities.PauseMode)
+}
+
+// ============================================================================
+//		SAVING
+// ============================================================================
+
+// EncodeEntity describes the entity and, if it is part of the graph, its
+// children. Entities queued for deletion are left out.
+func EncodeEntity(entity entities.IEntity) (EntityData, error) {
+	typeName, err := registry.typeName(entity)
+	if err != nil {
+		return EntityData{}, err
+	}
+
+	data := EntityData{
+		Type:            typeName,
+		Name:            entity.GetName(),
+		Position:        entity.GetPosition(),
+		Rotation:        entity.GetRotation(),
+		Scale:           entity.GetScale(),
+		DrawIndex:       entity.GetDrawIndex(),
+		LayerFlags:      entity.GetLayerFlags(),
+		Enabled:         entity.IsEnabled(),
+		Visible:         entity.IsVisible(),
+		ProcessPriority: entity.GetProcessPriority(),
+		PauseMode:       entity.GetPauseMode(),
+		Tags:            entity.GetTags(),
+		Properties:      encodeProperties(entity),
+	}
+
+	if node, ok := gemInstance.nodeMap[entity]; ok {
+		for _, child := range node.children {
+			if IsQueuedForDeletion(child.entity) {
+				continue
+			}
+			childData, err := EncodeEntity(child.entity)
+			if err != nil {
+				return EntityData{}, err
+			}
+			data.Children = append(data.Children, childData)
+		}
+	}
+	return data, nil
+}
+
+// SaveTree writes the children of the given entity and their subtrees to a
+// data file. The entity itself is not stored, so a scene root can be saved
+// and later loaded into a fresh root with LoadTree.
+func SaveTree(parent entities.IEntity, path string) error {
+	parentNode, ok := gemInstance.nodeMap[parent]
+	if !ok {
+		return fmt.Errorf("entity %v is not in the graph", parent.GetName())
+	}
+
+	tree := TreeData{Version: TreeFormatVersion, Entities: make([]EntityData, 0)}
+	for _, child := range parentNode.children {
+		if IsQueuedForDeletion(child.entity) {
+			continue
+		}
+		data, err := EncodeEntity(child.entity)
+		if err != nil {
+			return err
+		}
+		tree.Entities = append(tree.Entities, data)
+	}
+
+	content, err := json.MarshalIndent(tree, "", "    ")
+	if err != nil {
+		return err
+	}
+	// a cached copy of the old file would be loaded instead.
+	assets.Unload(path)
+	return os.WriteFile(path, content, 0644)
+}
+
+// ============================================================================
+//		LOADING
+// ============================================================================
+
+// builtEntity is an entity created from EntityData, waiting to be appended
+// to the graph together with its children.
+type builtEntity struct {
+	entity   entities.IEntity
+	children []builtEntity
+}
+
+// SpawnEntity creates the entity described by data and its children, and
+// appends them below parent. The whole subtree is created before anything
+// is appended, so an invalid description leaves the graph untouched.
+//
+// Children are appended after their parent, so they are not yet available in
+// the parent's Init or OnReady.
+func SpawnEntity(parent entities.IEntity, data EntityData) (entities.IEntity, error) {
+	built, err := buildEntity(data)
+	if err != nil {
+		return nil, err
+	}
+	appendBuilt(parent, built)
+	return built.entity, nil
+}
+
+// LoadTree reads a data file written by SaveTree and spawns its entities
+// below the given parent. It returns the spawned top level entities.
+func LoadTree(parent entities.IEntity, path string) ([]entities.IEntity, error) {
+	tree, err := ReadTree(path)
+	if err != nil {
+		return nil, err
+	}
+
+	built := make([]builtEntity, len(tree.Entities))
+	for i, data := range tree.Entities {
+		if built[i], err = buildEntity(data); err != nil {
+			return nil, fmt.Errorf("%v: %w", path, err)
+		}
+	}
+
+	spawned := make([]entities.IEntity, len(built))
+	for i, b := range built {
+		appendBuilt(parent, b)
+		spawned[i] = b.entity
+	}
+	return spawned, nil
+}
+
+// ReadTree reads and decodes a data file without spawning anything. The file
+// is read through the asset cache, so it can be preloaded, see
+// assets.StartPreload.
+func ReadTree(path string) (TreeData, error) {
+	content, err := assets.ReadFile(path)
+	if err != nil {
+		return TreeData{}, err
+	}
+
+	var tree TreeData
+	if err := json.Unmarshal(content, &tree); err != nil {
+		return TreeData{}, fmt.Errorf("%v: %w", path, err)
+	}
+	if tree.Version > TreeFormatVersion {
+		return TreeData{}, fmt.Errorf("%v: unsupported format version %v", path, tree.Version)
+	}
+	return tree, nil
+}
+
+// buildEntity expands all prefab references in data and creates the
+// described entities.
+func buildEntity(data EntityData) (builtEntity, error) {
+	expanded, err := expandPrefabs(data, nil)
+	if err != nil {
+		return builtEntity{}, err
+	}
+	return buildExpanded(expanded)
+}
+
+func buildExpanded(data EntityData) (builtEntity, error) {
+	entity, err := registry.newEntityOfType(data.Type)
+	if err != nil {
+		return builtEntity{}, err
+	}
+	if err := applyEntityData(entity, data); err != nil {
+		return builtEntity{}, err
+	}
+
+	built := builtEntity{entity: entity, children: make([]builtEntity, len(data.Children))}
+	for i, childData := range data.Children {
+		if built.children[i], err = buildExpanded(childData); err != nil {
+			return builtEntity{}, err
+		}
+	}
+	return built, nil
+}
+
+func appendBuilt(parent entities.IEntity, built builtEntity) {
+	Append(parent, built.entity)
+	for _, child := range built.children {
+		appendBuilt(built.entity, child)
+	}
+}
+
+// applyEntityData sets the base entity fields and properties of a newly
+// created entity.
+func applyEntityData(entity entities.IEntity, data EntityData) error {
+	setters, ok := entity.(entitySetters)
+	if !ok {
+		return fmt.Errorf("entity type %v does not embed entities.Entity", data.Type)
+	}
+
+	if data.Name != "" {
+		setters.SetName(data.Name)
+	}
+	entity.SetPosition(data.Position)
+	entity.SetRotation(data.Rotation)
+	entity.SetScale(data.Scale)
+	entity.SetDrawIndex(data.DrawIndex)
+	setters.SetLayerFlags(data.LayerFlags)
+	setters.SetEnabled(data.Enabled)
+	setters.SetVisible(data.Visible)
+	setters.SetProcessPriority(data.ProcessPriority)
+	setters.SetPauseMode(data.PauseMode)
+	for _, tag := range data.Tags {
+		entity.AddTag(tag)
+	}
+
+	return decodeProperties(entity, data.Properties)
+}
+
+// ============================================================================
+//		PROPERTIES
+// ============================================================================
+
+// propertyFields returns the struct fields of the entity tagged with
+// PropertyTag, keyed by their property name.
+func propertyFields(entity entities.IEntity) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+
+	v := reflect.ValueOf(entity)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fields
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, ok := field.Tag.Lookup(PropertyTag)
+		if !ok || name == "-" || !field.IsExported() {
+			continue
+		}
+		fields[name] = v.Field(i)
+	}
+	return fields
+}
+
+func encodeProperties(entity entities.IEntity) map[string]any {
+	fields := propertyFields(entity)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	properties := make(map[string]any, len(fields))
+	for name, field := range fields {
+		properties[name] = field.Interface()
+	}
+	return properties
+}
+
+func decodeProperties(entity entities.IEntity, properties map[string]any) error {
+	fields := propertyFields(entity)
+	for name, value := range properties {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("entity type %T has no property %q", entity, name)
+		}
+		// round trip through json to convert the generic decoded value into
+		// the field's type.
+		content, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(content, field.Addr().Interface()); err != nil {
+			return fmt.Errorf("property %q of %T: %w", name, entity, err)
+		}
+	}
+	return nil
+}
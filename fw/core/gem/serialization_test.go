@@ -0,0 +1,82 @@
+package gem
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorl/fw/core/entities"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type propEntity struct {
+	*entities.Entity
+	Speed  float32  `prop:"speed"`
+	Path   []string `prop:"path"`
+	hidden int
+}
+
+func newPropEntity() *propEntity {
+	return &propEntity{Entity: newPlainEntity("propEntity"), Speed: 1}
+}
+
+func TestSaveAndLoadTree(t *testing.T) {
+	Init()
+	registry = newTypeRegistry()
+	RegisterType("propEntity", newPropEntity)
+
+	scene := newPlainEntity("scene")
+	Append(GetRoot(), scene)
+	parent := newPropEntity()
+	parent.SetName("parent")
+	parent.SetPosition(rl.NewVector2(10, 20))
+	parent.SetDrawIndex(3)
+	parent.AddTag("enemy")
+	parent.Speed = 2.5
+	parent.Path = []string{"a", "b"}
+	parent.hidden = 7
+	Append(scene, parent)
+	Append(parent, newPlainEntity("child"))
+
+	path := filepath.Join(t.TempDir(), "scene.json")
+	if err := SaveTree(scene, path); err != nil {
+		t.Fatalf("failed to save tree: %v", err)
+	}
+
+	loaded := newPlainEntity("loaded")
+	Append(GetRoot(), loaded)
+	if _, err := LoadTree(loaded, path); err != nil {
+		t.Fatalf("failed to load tree: %v", err)
+	}
+
+	restored, ok := Find("loaded/parent").(*propEntity)
+	if !ok {
+		t.Fatalf("expected loaded/parent to be a *propEntity")
+	}
+	if restored.GetPosition() != rl.NewVector2(10, 20) || restored.GetDrawIndex() != 3 {
+		t.Errorf("base properties were not restored")
+	}
+	if !restored.HasTag("enemy") || len(FindByTag("enemy")) != 2 {
+		t.Errorf("tags were not restored")
+	}
+	if restored.Speed != 2.5 || len(restored.Path) != 2 || restored.hidden != 0 {
+		t.Errorf("unexpected properties, got speed %v, path %v, hidden %v",
+			restored.Speed, restored.Path, restored.hidden)
+	}
+	if Find("loaded/parent/child") == nil {
+		t.Errorf("expected the child to be restored")
+	}
+}
+
+func TestLoadTreeRejectsUnknownTypes(t *testing.T) {
+	Init()
+	registry = newTypeRegistry()
+
+	_, err := SpawnEntity(GetRoot(), EntityData{Type: "missing"})
+	if err == nil {
+		t.Errorf("expected an error for an unregistered type")
+	}
+	if len(GetChildren(GetRoot())) != 0 {
+		t.Errorf("expected nothing to be appended")
+	}
+}
@@ -1,83 +1,163 @@
 package gem
 
 import (
+	"cmp"
+	"slices"
+
 	"gorl/fw/core/datastructures"
 	"gorl/fw/core/entities"
 	input "gorl/fw/core/input/input_handling"
 	"gorl/fw/core/logging"
 	"gorl/fw/core/math"
 	"gorl/fw/core/render"
+	"gorl/fw/core/store"
 )
 
 // GetAbsoluteTransform returns the absolute transform of the entity.
+// The underlying matrix is cached and only recalculated after the entity or
+// one of its parents was moved.
 func GetAbsoluteTransform(entity entities.IEntity) math.Transform2D {
 	entityNode, ok := gemInstance.nodeMap[entity]
 	if !ok {
 		logging.Error("Tried to get absolute transform for entity not existent in gem.")
 		return math.Transform2DZero()
 	}
+	return math.NewTransform2DFromMatrix3(entityNode.GetWorldMatrix())
+}
 
-	// step through parents until we arrive at the root.
-	transformMat3 := entity.GetTransform().GenerateMatrix()
-	for entityNode.parent != gemInstance.root && entityNode.parent != nil { // we do a nil check just for good measure, normally it should stop at the root.
-		parentMat3 := entityNode.parent.entity.GetTransform().GenerateMatrix()
-		transformMat3 = transformMat3.Multiply(parentMat3)
-		entityNode = entityNode.parent
+// Traverse traverses through the entity graph, updating the entities.
+// In the process, it produces a list of DrawableEntity objects.
+// Structural changes made during the traversal are deferred, see
+// FlushCommands.
+//
+// Update is called on all processed entities first, LateUpdate afterwards,
+// both ordered by process priority. Entities that are not processed due to
+// their pause mode are still drawn, but don't receive input.
+func Traverse() ([]render.Drawable, []input.InputReceiver) {
+	beginTraversal()
+	defer endTraversal()
+
+	enabled, processed := collectNodes(isPaused())
+
+	for _, node := range sortedByPriority(processed) {
+		node.entity.Update()
+	}
+	for _, node := range sortedByPriority(processed) {
+		node.entity.LateUpdate()
+	}
+
+	drawables := make([]render.Drawable, 0, len(enabled))
+	for _, node := range enabled {
+		drawables = append(drawables, WrappedEntity{IEntity: node.entity})
 	}
 
-	return math.NewTransform2DFromMatrix3(transformMat3)
+	inputReceivers := make([]input.InputReceiver, 0, len(processed))
+	for _, node := range processed {
+		inputReceivers = append(inputReceivers, node.entity)
+	}
+
+	return drawables, inputReceivers
 }
 
-// Traverse traverses through the entity graph, updating the entities.
-// In the process, it produces a list of DrawableEntity objects.
-func Traverse(withFixedUpdate bool) ([]render.Drawable, []input.InputReceiver) {
+// FixedTraverse traverses through the entity graph, calling FixedUpdate on
+// all processed entities, ordered by process priority. The game loop calls
+// this once per fixed step, which may be zero or several times per frame.
+func FixedTraverse() {
+	beginTraversal()
+	defer endTraversal()
 
-	root := gemInstance.root
+	_, processed := collectNodes(isPaused())
+	for _, node := range sortedByPriority(processed) {
+		node.entity.FixedUpdate()
+	}
+}
 
-	nodeStack := datastructures.NewStack[*gemNode](len(gemInstance.nodeMap))
-	nodeStack.Push(root)
+// isPaused returns the global pause state from the store.
+func isPaused() bool {
+	appState, ok := store.Get[*store.AppState]()
+	return ok && appState.Paused
+}
 
-	transformStack := datastructures.NewStack[math.Matrix3](len(gemInstance.nodeMap))
-	transformStack.Push(math.Matrix3Identity())
+// SetFrozen stops or resumes processing the entity and its whole subtree.
+// Unlike PauseModeDisabled, children can't override this with their own pause
+// mode. Frozen entities are still drawn. The scene stack freezes covered
+// scenes this way.
+func SetFrozen(entity entities.IEntity, frozen bool) {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		logging.Error("entity not found in graph, can't freeze it")
+		return
+	}
+	node.frozen = frozen
+}
+
+// IsFrozen returns true if the entity or one of its ancestors is frozen.
+func IsFrozen(entity entities.IEntity) bool {
+	for node := gemInstance.nodeMap[entity]; node != nil; node = node.parent {
+		if node.frozen {
+			return true
+		}
+	}
+	return false
+}
 
-	drawables := make([]render.Drawable, 0, len(gemInstance.nodeMap)/2)
-	inputReceivers := make([]input.InputReceiver, 0, len(gemInstance.nodeMap)/2)
+// collectNodes walks the graph in tree order, parents before their children
+// and siblings in the order they were added. It returns all enabled nodes,
+// and the subset of them that should be processed in the given pause state.
+// Disabled entities are skipped together with their children, frozen ones are
+// only not processed.
+func collectNodes(paused bool) (enabled, processed []*gemNode) {
+	enabled = make([]*gemNode, 0, len(gemInstance.nodeMap))
+	processed = make([]*gemNode, 0, len(gemInstance.nodeMap))
 
-	for !nodeStack.IsEmpty() {
+	nodeStack := datastructures.NewStack[*gemNode](len(gemInstance.nodeMap))
+	nodeStack.Push(gemInstance.root)
+
+	// the resolved pause mode of each node on the stack, and whether it is
+	// frozen, so children can inherit them.
+	type inherited struct {
+		mode   entities.PauseMode
+		frozen bool
+	}
+	inheritStack := datastructures.NewStack[inherited](len(gemInstance.nodeMap))
+	inheritStack.Push(inherited{mode: entities.PauseModePausable})
 
+	for !nodeStack.IsEmpty() {
 		node, _ := nodeStack.Pop()
-		tMat3, _ := transformStack.Pop()
+		parent, _ := inheritStack.Pop()
+		mode, frozen := parent.mode, parent.frozen || node.frozen
 
 		// if the entity is not enabled, skip it and its children
 		if !node.entity.IsEnabled() {
 			continue
 		}
 
-		// add the entity to the input receivers
-		if node.entity.IsEnabled() {
-			inputReceivers = append(inputReceivers, node.entity)
+		if own := node.entity.GetPauseMode(); own != entities.PauseModeInherit {
+			mode = own
 		}
 
-		// Update the entity
-		node.entity.Update()
-		if withFixedUpdate {
-			node.entity.FixedUpdate()
+		enabled = append(enabled, node)
+		if !frozen && mode.ShouldProcess(paused) {
+			processed = append(processed, node)
 		}
 
-		drawables = append(drawables, WrappedEntity{
-			IEntity:      node.entity,
-			absTransform: math.NewTransform2DFromMatrix3(tMat3),
-		})
-
-		for _, child := range node.children {
-			nodeStack.Push(child)
-			transformStack.Push( // we push M_child * M_stack
-				child.entity.
-					GetTransform().
-					GenerateMatrix().
-					Multiply(tMat3))
+		// push in reverse, so the first child is popped first.
+		for i := len(node.children) - 1; i >= 0; i-- {
+			nodeStack.Push(node.children[i])
+			inheritStack.Push(inherited{mode, frozen})
 		}
 	}
 
-	return drawables, inputReceivers
+	return enabled, processed
+}
+
+// sortedByPriority returns a copy of the nodes, stably sorted by the process
+// priority of their entities.
+func sortedByPriority(nodes []*gemNode) []*gemNode {
+	sorted := make([]*gemNode, len(nodes))
+	copy(sorted, nodes)
+	slices.SortStableFunc(sorted, func(l, r *gemNode) int {
+		return cmp.Compare(l.entity.GetProcessPriority(), r.entity.GetProcessPriority())
+	})
+	return sorted
 }
@@ -0,0 +1,99 @@
+package gem
+
+import (
+	"reflect"
+	"testing"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/store"
+)
+
+// processEntity records its Update and LateUpdate calls into a shared log.
+type processEntity struct {
+	*entities.Entity
+	log *[]string
+}
+
+func newProcessEntity(name string, priority int32, log *[]string) *processEntity {
+	ent := &processEntity{Entity: newPlainEntity(name), log: log}
+	ent.SetProcessPriority(priority)
+	return ent
+}
+
+func (ent *processEntity) Update()     { *ent.log = append(*ent.log, ent.GetName()+":update") }
+func (ent *processEntity) LateUpdate() { *ent.log = append(*ent.log, ent.GetName()+":late") }
+
+func TestProcessPriorityOrder(t *testing.T) {
+	Init()
+	log := []string{}
+	a := newProcessEntity("a", 0, &log)
+	b := newProcessEntity("b", -1, &log)
+	c := newProcessEntity("c", 0, &log)
+	Append(GetRoot(), a)
+	Append(a, b)
+	Append(GetRoot(), c)
+
+	Traverse()
+
+	expected := []string{
+		"b:update", "a:update", "c:update",
+		"b:late", "a:late", "c:late",
+	}
+	if !reflect.DeepEqual(log, expected) {
+		t.Errorf("unexpected process order:\n got %v\nwant %v", log, expected)
+	}
+}
+
+func TestPauseModes(t *testing.T) {
+	Init()
+	appState, _ := store.Get[*store.AppState]()
+	appState.Paused = true
+	defer func() { appState.Paused = false }()
+
+	log := []string{}
+	menu := newProcessEntity("menu", 0, &log)
+	menu.SetPauseMode(entities.PauseModeWhenPaused)
+	button := newProcessEntity("button", 0, &log)
+	world := newProcessEntity("world", 0, &log)
+	Append(GetRoot(), menu)
+	Append(menu, button)
+	Append(GetRoot(), world)
+
+	drawables, inputReceivers := Traverse()
+
+	expected := []string{"menu:update", "button:update", "menu:late", "button:late"}
+	if !reflect.DeepEqual(log, expected) {
+		t.Errorf("unexpected processed entities while paused:\n got %v\nwant %v", log, expected)
+	}
+	// paused entities are still drawn, but don't receive input
+	if len(drawables) != 4 || len(inputReceivers) != 2 {
+		t.Errorf("expected 4 drawables and 2 input receivers, got %d and %d",
+			len(drawables), len(inputReceivers))
+	}
+}
+
+func TestFrozenSubtreesAreNotProcessed(t *testing.T) {
+	Init()
+	log := []string{}
+	scene := newProcessEntity("scene", 0, &log)
+	hud := newProcessEntity("hud", 0, &log)
+	hud.SetPauseMode(entities.PauseModeAlways)
+	Append(GetRoot(), scene)
+	Append(scene, hud)
+	SetFrozen(scene, true)
+
+	drawables, inputReceivers := Traverse()
+	// only the root still receives input.
+	if len(log) != 0 || len(inputReceivers) != 1 || !IsFrozen(hud) {
+		t.Errorf("frozen subtree should not be processed, even with an own pause mode, got %v", log)
+	}
+	if len(drawables) != 3 {
+		t.Errorf("frozen entities should still be drawn, got %d drawables", len(drawables))
+	}
+
+	SetFrozen(scene, false)
+	Traverse()
+	if len(log) == 0 {
+		t.Errorf("thawed subtree should be processed again")
+	}
+}
@@ -0,0 +1,52 @@
+package gem
+
+import (
+	"gorl/fw/core/entities"
+	"gorl/fw/core/math"
+)
+
+// gemNode is handed to its entity as the entity's link into the graph.
+var _ entities.TreeNode = (*gemNode)(nil)
+
+// GetWorldMatrix returns the global transformation matrix of the node,
+// recalculating it and the matrices of its dirty ancestors if needed.
+func (n *gemNode) GetWorldMatrix() math.Matrix3 {
+	if n.worldDirty {
+		local := n.entity.GetTransform().GenerateMatrix()
+		n.worldMatrix = n.GetParentWorldMatrix().Multiply(local)
+		n.worldDirty = false
+	}
+	return n.worldMatrix
+}
+
+// GetParentWorldMatrix returns the global transformation matrix of the
+// node's parent, or the identity matrix for the root.
+func (n *gemNode) GetParentWorldMatrix() math.Matrix3 {
+	if n.parent == nil {
+		return math.Matrix3Identity()
+	}
+	return n.parent.GetWorldMatrix()
+}
+
+// MarkTransformDirty invalidates the cached world matrix of the node and of
+// all its descendants.
+func (n *gemNode) MarkTransformDirty() {
+	// a dirty node only has dirty descendants, so there is nothing left to
+	// do below it.
+	if n.worldDirty {
+		return
+	}
+	n.worldDirty = true
+	for _, child := range n.children {
+		child.MarkTransformDirty()
+	}
+}
+
+// TagChanged keeps the tag index of the gem up to date.
+func (n *gemNode) TagChanged(tag string, added bool) {
+	if added {
+		gemInstance.tagIndex[tag] = append(gemInstance.tagIndex[tag], n)
+	} else {
+		removeFromIndex(gemInstance.tagIndex, tag, n)
+	}
+}
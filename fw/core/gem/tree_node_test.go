@@ -0,0 +1,47 @@
+package gem
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func almostEqualVector2(a, b rl.Vector2) bool {
+	return rl.Vector2Distance(a, b) < 0.001
+}
+
+func TestGlobalPosition(t *testing.T) {
+	Init()
+	parent := newPlainEntity("parent")
+	parent.SetPosition(rl.NewVector2(100, 0))
+	parent.SetRotation(90)
+	Append(GetRoot(), parent)
+	child := newPlainEntity("child")
+	child.SetPosition(rl.NewVector2(10, 0))
+	Append(parent, child)
+
+	if pos := child.GetGlobalPosition(); !almostEqualVector2(pos, rl.NewVector2(100, 10)) {
+		t.Errorf("expected global position (100, 10), got %v", pos)
+	}
+
+	// moving the parent must invalidate the cached transform of the child.
+	parent.SetPosition(rl.NewVector2(0, 0))
+	if pos := child.GetGlobalPosition(); !almostEqualVector2(pos, rl.NewVector2(0, 10)) {
+		t.Errorf("expected global position (0, 10) after moving the parent, got %v", pos)
+	}
+
+	child.SetGlobalPosition(rl.NewVector2(-20, 0))
+	if pos := child.GetPosition(); !almostEqualVector2(pos, rl.NewVector2(0, 20)) {
+		t.Errorf("expected local position (0, 20), got %v", pos)
+	}
+	if pos := child.GetGlobalPosition(); !almostEqualVector2(pos, rl.NewVector2(-20, 0)) {
+		t.Errorf("expected global position (-20, 0), got %v", pos)
+	}
+
+	// reparenting to the root keeps the local position, so the global one
+	// changes.
+	ReParent(child, GetRoot())
+	if pos := child.GetGlobalPosition(); !almostEqualVector2(pos, rl.NewVector2(0, 20)) {
+		t.Errorf("expected global position (0, 20) after reparenting, got %v", pos)
+	}
+}
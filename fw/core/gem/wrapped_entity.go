@@ -9,9 +9,10 @@ import (
 
 var _ render.Drawable = &WrappedEntity{}
 
+// WrappedEntity adapts an entity in the graph to the render.Drawable
+// interface.
 type WrappedEntity struct {
 	entities.IEntity
-	absTransform math.Transform2D
 }
 
 // ShouldDraw checks if the entity should be drawn based on its layer flags,
@@ -23,12 +24,10 @@ func (d WrappedEntity) ShouldDraw(layerFlags math.BitFlag) bool {
 	return e && v && f
 }
 
-// Draw draws the entity.
+// Draw draws the entity. Entities draw at their global transform, see
+// entities.Entity.GetGlobalTransform.
 func (d WrappedEntity) Draw() {
-	oldTransform := *d.IEntity.GetTransform() // save the entity's old *local* transform
-	d.IEntity.SetTransform(d.absTransform)    // set the entity's transform to the new transform matrix
-	d.IEntity.Draw()                          // draw the entity
-	d.IEntity.SetTransform(oldTransform)      // restore the entity's old *local* transform
+	d.IEntity.Draw()
 }
 
 // GetEntity retrieves the wrapped entity.
@@ -41,7 +40,8 @@ func (d WrappedEntity) AsDrawable() render.Drawable {
 	return d
 }
 
-// AsInputReceiver returns the entity as an input receiver.
+// AsInputReceiver returns the entity as an input receiver. This is the
+// entity itself, so it matches the receivers returned by Traverse.
 func (d WrappedEntity) AsInputReceiver() input.InputReceiver {
-	return d
+	return d.IEntity
 }
@@ -1,63 +1,206 @@
 package input
 
-import rl "github.com/gen2brain/raylib-go/raylib"
+import (
+	"fmt"
+	"slices"
+	"strings"
 
-// Actions
-type Action int32
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Action is the name of an abstract action like "jump" or "shoot". Actions
+// are registered at runtime with RegisterAction, and bound to the triggers
+// that cause them.
+type Action string
 
+// Actions registered by the framework.
 const (
-	ActionMoveUp Action = iota
-	ActionMoveDown
-	ActionMoveLeft
-	ActionMoveRight
-	ActionClickDown
-	ActionClickHeld
-	ActionClickUp
-	ActionMouseHover
-	ActionEscape
-	// Add other actions as needed
-	ActionZoomIn
-	ActionZoomOut
-	ActionNextAnimation
+	ActionMoveUp        Action = "move_up"
+	ActionMoveDown      Action = "move_down"
+	ActionMoveLeft      Action = "move_left"
+	ActionMoveRight     Action = "move_right"
+	ActionClickDown     Action = "click_down"
+	ActionClickHeld     Action = "click_held"
+	ActionClickUp       Action = "click_up"
+	ActionMouseHover    Action = "mouse_hover"
+	ActionEscape        Action = "escape"
+	ActionZoomIn        Action = "zoom_in"
+	ActionZoomOut       Action = "zoom_out"
+	ActionNextAnimation Action = "next_animation"
+
+	// ActionMove is a vector action combining the four move actions.
+	ActionMove Action = "move"
+
+	// ActionGamepadConnected and ActionGamepadDisconnected are sent when a
+	// gamepad is plugged in or out. They have no triggers.
+	ActionGamepadConnected    Action = "gamepad_connected"
+	ActionGamepadDisconnected Action = "gamepad_disconnected"
 )
 
-var ActionMap = map[Action][]Trigger{
-	ActionMoveUp: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyW},
-	},
-	ActionMoveDown: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyS},
-	},
-	ActionMoveLeft: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyA},
-	},
-	ActionMoveRight: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyD},
-	},
-	ActionClickDown: {
-		{InputType: InputTypeMouse, TriggerType: TriggerTypePressed, MouseButton: rl.MouseLeftButton},
-	},
-	ActionClickHeld: {
-		{InputType: InputTypeMouse, TriggerType: TriggerTypeDown, MouseButton: rl.MouseLeftButton},
-	},
-	ActionClickUp: {
-		{InputType: InputTypeMouse, TriggerType: TriggerTypeReleased, MouseButton: rl.MouseLeftButton},
-	},
-	ActionMouseHover: {
-		{InputType: InputTypeMouse, TriggerType: TriggerTypePassive},
-	},
-	ActionEscape: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyEscape},
-	},
-
-	ActionZoomIn: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyQ},
-	},
-	ActionZoomOut: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyE},
-	},
-	ActionNextAnimation: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypePressed, Key: rl.KeyN},
-	},
-	// Add other action-trigger mappings
+func init() {
+	RegisterAction(ActionMoveUp,
+		Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyW},
+		Trigger{InputType: InputTypeGamepad, TriggerType: TriggerTypeDown, GamepadButton: rl.GamepadButtonLeftFaceUp},
+		Trigger{InputType: InputTypeGamepadAxis, TriggerType: TriggerTypeDown, GamepadAxis: rl.GamepadAxisLeftY, AxisThreshold: -0.1})
+	RegisterAction(ActionMoveDown,
+		Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyS},
+		Trigger{InputType: InputTypeGamepad, TriggerType: TriggerTypeDown, GamepadButton: rl.GamepadButtonLeftFaceDown},
+		Trigger{InputType: InputTypeGamepadAxis, TriggerType: TriggerTypeDown, GamepadAxis: rl.GamepadAxisLeftY, AxisThreshold: 0.1})
+	RegisterAction(ActionMoveLeft,
+		Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyA},
+		Trigger{InputType: InputTypeGamepad, TriggerType: TriggerTypeDown, GamepadButton: rl.GamepadButtonLeftFaceLeft},
+		Trigger{InputType: InputTypeGamepadAxis, TriggerType: TriggerTypeDown, GamepadAxis: rl.GamepadAxisLeftX, AxisThreshold: -0.1})
+	RegisterAction(ActionMoveRight,
+		Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyD},
+		Trigger{InputType: InputTypeGamepad, TriggerType: TriggerTypeDown, GamepadButton: rl.GamepadButtonLeftFaceRight},
+		Trigger{InputType: InputTypeGamepadAxis, TriggerType: TriggerTypeDown, GamepadAxis: rl.GamepadAxisLeftX, AxisThreshold: 0.1})
+	RegisterAction(ActionClickDown,
+		Trigger{InputType: InputTypeMouse, TriggerType: TriggerTypePressed, MouseButton: rl.MouseLeftButton})
+	RegisterAction(ActionClickHeld,
+		Trigger{InputType: InputTypeMouse, TriggerType: TriggerTypeDown, MouseButton: rl.MouseLeftButton})
+	RegisterAction(ActionClickUp,
+		Trigger{InputType: InputTypeMouse, TriggerType: TriggerTypeReleased, MouseButton: rl.MouseLeftButton})
+	RegisterAction(ActionMouseHover,
+		Trigger{InputType: InputTypeMouse, TriggerType: TriggerTypePassive})
+	RegisterAction(ActionEscape,
+		Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyEscape})
+	RegisterAction(ActionZoomIn,
+		Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyQ})
+	RegisterAction(ActionZoomOut,
+		Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyE})
+	RegisterAction(ActionNextAnimation,
+		Trigger{InputType: InputTypeKey, TriggerType: TriggerTypePressed, Key: rl.KeyN})
+	RegisterAction(ActionGamepadConnected)
+	RegisterAction(ActionGamepadDisconnected)
+	RegisterVectorAction(ActionMove, ActionMoveLeft, ActionMoveRight, ActionMoveUp, ActionMoveDown)
+}
+
+// actionMap holds the registered actions in registration order, so input
+// events are produced in a stable order every frame.
+type actionMap struct {
+	actions  []Action
+	bindings map[Action][]Trigger
+	defaults map[Action][]Trigger
+}
+
+var actions = &actionMap{
+	bindings: make(map[Action][]Trigger),
+	defaults: make(map[Action][]Trigger),
+}
+
+// ConflictError is returned when binding a trigger that is already bound to
+// other actions.
+type ConflictError struct {
+	Trigger Trigger
+	Actions []Action
+}
+
+func (e *ConflictError) Error() string {
+	names := make([]string, len(e.Actions))
+	for i, action := range e.Actions {
+		names[i] = string(action)
+	}
+	return fmt.Sprintf("trigger is already bound to %v", strings.Join(names, ", "))
+}
+
+// RegisterAction registers a new action with its default triggers.
+// Registering an action again replaces its defaults and current bindings.
+func RegisterAction(action Action, defaults ...Trigger) {
+	if !IsActionRegistered(action) {
+		actions.actions = append(actions.actions, action)
+	}
+	actions.defaults[action] = slices.Clone(defaults)
+	actions.bindings[action] = slices.Clone(defaults)
+}
+
+// IsActionRegistered returns true if the action was registered.
+func IsActionRegistered(action Action) bool {
+	_, ok := actions.bindings[action]
+	return ok
+}
+
+// GetActions returns all registered actions, in registration order.
+func GetActions() []Action {
+	return slices.Clone(actions.actions)
+}
+
+// GetBindings returns the triggers currently bound to the action.
+func GetBindings(action Action) []Trigger {
+	return slices.Clone(actions.bindings[action])
+}
+
+// FindConflicts returns the actions other than the given one that the trigger
+// is already bound to.
+func FindConflicts(action Action, trigger Trigger) []Action {
+	conflicts := []Action{}
+	for _, other := range actions.actions {
+		if other != action && slices.Contains(actions.bindings[other], trigger) {
+			conflicts = append(conflicts, other)
+		}
+	}
+	return conflicts
+}
+
+// Bind adds a trigger to the action. If the trigger is already bound to other
+// actions, nothing is changed and a *ConflictError is returned, so the
+// caller can ask the player to resolve it. Invalid triggers are rejected,
+// see Trigger.Validate.
+func Bind(action Action, trigger Trigger) error {
+	if !IsActionRegistered(action) {
+		return fmt.Errorf("action %q is not registered", action)
+	}
+	if err := trigger.Validate(); err != nil {
+		return err
+	}
+	if conflicts := FindConflicts(action, trigger); len(conflicts) > 0 {
+		return &ConflictError{Trigger: trigger, Actions: conflicts}
+	}
+	if !slices.Contains(actions.bindings[action], trigger) {
+		actions.bindings[action] = append(actions.bindings[action], trigger)
+	}
+	return nil
+}
+
+// Unbind removes a trigger from the action.
+func Unbind(action Action, trigger Trigger) {
+	if !IsActionRegistered(action) {
+		return
+	}
+	actions.bindings[action] = slices.DeleteFunc(actions.bindings[action], func(t Trigger) bool {
+		return t == trigger
+	})
+}
+
+// Rebind replaces a trigger of the action with another one. See Bind for how
+// conflicts are handled. Rebinding a trigger to itself changes nothing.
+func Rebind(action Action, old, new Trigger) error {
+	if !IsActionRegistered(action) {
+		return fmt.Errorf("action %q is not registered", action)
+	}
+	if old == new {
+		return nil
+	}
+	if err := new.Validate(); err != nil {
+		return err
+	}
+	if conflicts := FindConflicts(action, new); len(conflicts) > 0 {
+		return &ConflictError{Trigger: new, Actions: conflicts}
+	}
+	if slices.Contains(actions.bindings[action], new) {
+		Unbind(action, old)
+		return nil
+	}
+	i := slices.Index(actions.bindings[action], old)
+	if i < 0 {
+		return Bind(action, new)
+	}
+	actions.bindings[action][i] = new
+	return nil
+}
+
+// ResetBindings restores the default triggers of all actions.
+func ResetBindings() {
+	for _, action := range actions.actions {
+		actions.bindings[action] = slices.Clone(actions.defaults[action])
+	}
 }
@@ -0,0 +1,111 @@
+package input
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestBindAndRebind(t *testing.T) {
+	jump := Action("jump")
+	space := Trigger{InputType: InputTypeKey, TriggerType: TriggerTypePressed, Key: rl.KeySpace}
+	keyW := Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyW}
+	RegisterAction(jump, space)
+	defer ResetBindings()
+
+	var conflict *ConflictError
+	if err := Bind(jump, keyW); !errors.As(err, &conflict) || conflict.Actions[0] != ActionMoveUp {
+		t.Errorf("expected a conflict with %v, got %v", ActionMoveUp, err)
+	}
+
+	stick := Trigger{InputType: InputTypeGamepadAxis, TriggerType: TriggerTypeDown, GamepadAxis: rl.GamepadAxisRightY}
+	if err := Bind(jump, stick); err == nil || stick.IsAxisActive(0) {
+		t.Errorf("an axis trigger without a threshold should be rejected")
+	}
+
+	// confirming the current trigger keeps it.
+	if err := Rebind(jump, space, space); err != nil || !reflect.DeepEqual(GetBindings(jump), []Trigger{space}) {
+		t.Fatalf("rebinding to the same trigger should keep it, got %v, %v", GetBindings(jump), err)
+	}
+
+	Unbind(ActionMoveUp, keyW)
+	if err := Rebind(jump, space, keyW); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(GetBindings(jump), []Trigger{keyW}) {
+		t.Errorf("unexpected bindings %v", GetBindings(jump))
+	}
+
+	ResetBindings()
+	if !reflect.DeepEqual(GetBindings(jump), []Trigger{space}) {
+		t.Errorf("expected the default bindings, got %v", GetBindings(jump))
+	}
+}
+
+func TestSaveAndLoadBindings(t *testing.T) {
+	defer ResetBindings()
+	path := filepath.Join(t.TempDir(), "bindings.json")
+	arrowUp := Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyUp}
+
+	defaults := GetBindings(ActionMoveUp)
+	if len(defaults) != 3 {
+		t.Fatalf("unexpected default bindings %v", defaults)
+	}
+	if err := Rebind(ActionMoveUp, defaults[0], arrowUp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := SaveBindings(path); err != nil {
+		t.Fatalf("failed to save bindings: %v", err)
+	}
+
+	ResetBindings()
+	if err := LoadBindings(path); err != nil {
+		t.Fatalf("failed to load bindings: %v", err)
+	}
+	expected := append([]Trigger{arrowUp}, defaults[1:]...)
+	if bindings := GetBindings(ActionMoveUp); !reflect.DeepEqual(bindings, expected) {
+		t.Errorf("expected %v, got %v", expected, bindings)
+	}
+}
+
+func TestLoadBindingsRejectsConflicts(t *testing.T) {
+	defer ResetBindings()
+	path := filepath.Join(t.TempDir(), "bindings.json")
+	os.WriteFile(path, []byte(`{"version": 1, "bindings": {
+		"zoom_in": [{"inputType": "key", "triggerType": "down", "key": 87}],
+		"zoom_out": [{"inputType": "key", "triggerType": "down", "key": 82}],
+		"escape": [{"inputType": "key", "triggerType": "down", "key": 82}]
+	}}`), 0o644)
+	keyW := Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyW}
+	keyR := Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyR}
+
+	var conflict *ConflictError
+	if err := LoadBindings(path); !errors.As(err, &conflict) {
+		t.Fatalf("expected the conflicts to be reported, got %v", err)
+	}
+	if len(GetBindings(ActionZoomIn)) != 0 || !slices.Contains(GetBindings(ActionMoveUp), keyW) {
+		t.Errorf("a trigger bound to an action missing in the file should stay there, got %v", GetBindings(ActionZoomIn))
+	}
+	// escape was registered before zoom_out.
+	if !reflect.DeepEqual(GetBindings(ActionEscape), []Trigger{keyR}) || len(GetBindings(ActionZoomOut)) != 0 {
+		t.Errorf("a trigger should go to the first of the conflicting actions, got %v and %v",
+			GetBindings(ActionEscape), GetBindings(ActionZoomOut))
+	}
+}
+
+func TestModifiersText(t *testing.T) {
+	modifiers := ModifierCtrl | ModifierShift
+	text, err := modifiers.MarshalText()
+	if err != nil || string(text) != "shift+ctrl" {
+		t.Fatalf("unexpected text %q, error %v", text, err)
+	}
+	var parsed Modifiers
+	if err := parsed.UnmarshalText(text); err != nil || parsed != modifiers {
+		t.Errorf("expected %v, got %v, error %v", modifiers, parsed, err)
+	}
+}
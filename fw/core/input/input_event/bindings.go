@@ -0,0 +1,81 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// BindingsFormatVersion is the version of the file written by SaveBindings.
+const BindingsFormatVersion = 1
+
+// bindingsFile is the content of a bindings file.
+type bindingsFile struct {
+	Version  int                  `json:"version"`
+	Bindings map[Action][]Trigger `json:"bindings"`
+}
+
+// SaveBindings writes the current bindings of all actions to a JSON file.
+func SaveBindings(path string) error {
+	file := bindingsFile{
+		Version:  BindingsFormatVersion,
+		Bindings: make(map[Action][]Trigger, len(actions.actions)),
+	}
+	for _, action := range actions.actions {
+		file.Bindings[action] = GetBindings(action)
+	}
+
+	content, err := json.MarshalIndent(file, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// LoadBindings replaces the bindings of the actions stored in a file written
+// by SaveBindings. Actions missing in the file keep their current bindings.
+//
+// The triggers are bound with Bind, in the order the actions were registered,
+// so a trigger bound to several actions in the file only goes to the first
+// one. Such conflicts and actions in the file that are not registered are
+// returned as an error after everything else was applied.
+func LoadBindings(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file bindingsFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("%v: %w", path, err)
+	}
+	if file.Version > BindingsFormatVersion {
+		return fmt.Errorf("%v: unsupported format version %v", path, file.Version)
+	}
+
+	var errs []error
+	unknown := []Action{}
+	for action := range file.Bindings {
+		if !IsActionRegistered(action) {
+			unknown = append(unknown, action)
+		} else {
+			// unbound first, so the file's triggers only conflict with each
+			// other and with the actions missing in the file.
+			actions.bindings[action] = []Trigger{}
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		errs = append(errs, fmt.Errorf("%v: unknown actions %v", path, unknown))
+	}
+	for _, action := range actions.actions {
+		for _, trigger := range file.Bindings[action] {
+			if err := Bind(action, trigger); err != nil {
+				errs = append(errs, fmt.Errorf("%v: %v: %w", path, action, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
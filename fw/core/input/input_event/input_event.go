@@ -10,19 +10,84 @@ import (
 type InputEvent struct {
 	Action         Action
 	cursorPosition rl.Vector2
-	// TODO: there might be more fields necessary here, especially to support
-	// other input devices such as gamepads.
+
+	// Gamepad is the index of the gamepad that caused the event, or
+	// NoGamepad for keyboard and mouse events.
+	Gamepad int32
+	// Player is the player the event belongs to. Gamepads are routed to
+	// players, keyboard and mouse always belong to player 0.
+	Player int
+
+	// Strength is how much the action is performed, from 0 to 1, e.g. the
+	// tilt of a stick or the pressure on a trigger. Digital inputs always
+	// have a strength of 1, scroll wheel events the scrolled amount.
+	Strength float32
+	// Axis is the value of vector actions, see RegisterVectorAction. Its
+	// length is at most 1.
+	Axis rl.Vector2
+	// InputType is the type of the trigger that caused the event, e.g. to
+	// tell mouse clicks from key presses of the same action.
+	InputType InputType
+
+	// camera is the camera the receiver was drawn by, and worldPosition the
+	// cursor position in its world. Set by the dispatcher for each receiver.
+	camera        Viewport
+	worldPosition rl.Vector2
 }
 
+// Viewport is the part of the screen a camera draws to, implemented by
+// render.Camera.
+type Viewport interface {
+	// ScreenToWorld converts a screen position to a world position.
+	ScreenToWorld(screenPos rl.Vector2) rl.Vector2
+	// ContainsScreenPoint returns true if the camera draws to the screen
+	// position.
+	ContainsScreenPoint(screenPos rl.Vector2) bool
+}
+
+// NoGamepad is the Gamepad of events not caused by a gamepad.
+const NoGamepad int32 = -1
+
 func NewInputEvent(action Action, cursorPosition rl.Vector2) *InputEvent {
-	return &InputEvent{Action: action, cursorPosition: cursorPosition}
+	return &InputEvent{Action: action, cursorPosition: cursorPosition, Gamepad: NoGamepad, Strength: 1}
+}
+
+// NewGamepadInputEvent creates an event caused by the given gamepad, which is
+// routed to the given player.
+func NewGamepadInputEvent(action Action, cursorPosition rl.Vector2, gamepad int32, player int) *InputEvent {
+	return &InputEvent{Action: action, cursorPosition: cursorPosition, Gamepad: gamepad, Player: player, Strength: 1}
+}
+
+// WithCamera returns a copy of the event as seen through the camera, with
+// the world position of the cursor in it. A nil camera leaves the world
+// position at the screen position.
+func (e *InputEvent) WithCamera(camera Viewport) *InputEvent {
+	event := *e
+	event.camera = camera
+	event.worldPosition = e.cursorPosition
+	if camera != nil {
+		event.worldPosition = camera.ScreenToWorld(e.cursorPosition)
+	}
+	return &event
+}
+
+// GetCamera returns the camera that drew the receiver of the event, usually
+// a *render.Camera. It is nil for receivers that were not drawn.
+func (e *InputEvent) GetCamera() Viewport {
+	return e.camera
+}
+
+// GetWorldMousePosition returns the cursor position in the world of the
+// camera that drew the receiver, or the screen position if there is none.
+func (e *InputEvent) GetWorldMousePosition() rl.Vector2 {
+	if e.camera == nil {
+		return e.cursorPosition
+	}
+	return e.worldPosition
 }
 
+// GetScreenSpaceMousePosition returns the cursor position on the screen,
+// regardless of the camera that drew the receiver.
 func (e *InputEvent) GetScreenSpaceMousePosition() rl.Vector2 {
-	// FIXME: this is called during update and not during render, so there is not active render stage.
-	// Approaches:
-	// 1. make sure this function is called during render.
-	// 2. have only a single camera config for all stages, so we don't need to know the active stage.
-	// 3. have each entity know its render stage. this could be accomplished by "learning", e.g. every time the entity is drawn, it learns the current render stage.
 	return e.cursorPosition
 }
@@ -0,0 +1,30 @@
+package input
+
+import "slices"
+
+// A Sequence causes an action when its steps fire one after another, like
+// the combo inputs of fighting games. Steps are usually pressed triggers.
+type Sequence struct {
+	Action Action
+	Steps  []Trigger
+	// Window is the maximum time in seconds between two steps. Waiting
+	// longer starts the sequence over.
+	Window float32
+}
+
+var sequences = []Sequence{}
+
+// RegisterSequence adds a sequence of triggers that causes the action, in
+// addition to the triggers bound to it. Firing a step out of order starts
+// the sequence over. The action is registered if it is not yet.
+func RegisterSequence(action Action, window float32, steps ...Trigger) {
+	if !IsActionRegistered(action) {
+		RegisterAction(action)
+	}
+	sequences = append(sequences, Sequence{Action: action, Steps: slices.Clone(steps), Window: window})
+}
+
+// GetSequences returns all registered sequences, in registration order.
+func GetSequences() []Sequence {
+	return slices.Clone(sequences)
+}
@@ -1,5 +1,11 @@
 package input
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // TriggerType defines the type of event, e.g. down, pressed, released.
 type TriggerType int32
 
@@ -8,23 +14,164 @@ const (
 	TriggerTypePressed
 	TriggerTypeReleased
 	TriggerTypePassive // Passive triggers are always active
+	// TriggerTypeHold fires once after the button was held for Duration
+	// seconds.
+	TriggerTypeHold
+	// TriggerTypeDoubleTap fires when the button is pressed twice within
+	// Duration seconds.
+	TriggerTypeDoubleTap
+	// TriggerTypeRepeat fires when the button is pressed, then again after
+	// Duration seconds and every Interval seconds while it is held, like
+	// keys repeat in a text field.
+	TriggerTypeRepeat
+)
+
+// Modifiers are modifier keys that have to be held for a key or mouse
+// trigger to fire, e.g. ModifierCtrl for Ctrl+S. Either the left or the
+// right key counts. Other modifiers being held do not stop a trigger, unless
+// they complete a chord bound to the same key, e.g. Ctrl+S stops S.
+type Modifiers uint8
+
+const (
+	ModifierShift Modifiers = 1 << iota
+	ModifierCtrl
+	ModifierAlt
+	ModifierSuper
 )
 
 // InputType defines the physical type of trigger. It can be a key, mouse
-// button, or gamepad button.
+// button, gamepad button or gamepad axis.
 type InputType int32
 
 const (
 	InputTypeKey InputType = iota
 	InputTypeMouse
 	InputTypeGamepad
+	InputTypeGamepadAxis
+	InputTypeMouseWheel
 )
 
 // A Trigger is a definition of an input trigger that can cause an action. We
 // use it to map specific triggers to abstract actions.
 type Trigger struct {
-	InputType   InputType
-	TriggerType TriggerType
-	Key         int32
-	MouseButton int32
+	InputType     InputType   `json:"inputType"`
+	TriggerType   TriggerType `json:"triggerType"`
+	Key           int32       `json:"key,omitempty"`
+	MouseButton   int32       `json:"mouseButton,omitempty"`
+	GamepadButton int32       `json:"gamepadButton,omitempty"`
+	GamepadAxis   int32       `json:"gamepadAxis,omitempty"`
+
+	// AxisThreshold is the value a gamepad axis or the mouse wheel has to
+	// reach for the trigger to be down. A negative threshold is reached by
+	// moving the axis below it, e.g. -0.5 on GamepadAxisLeftY for tilting
+	// the stick up. Use a small threshold for analog movement, so the
+	// strength of the action follows the axis. It must not be 0, see
+	// Validate.
+	AxisThreshold float32 `json:"axisThreshold,omitempty"`
+
+	Modifiers Modifiers `json:"modifiers,omitempty"`
+	// Duration is the hold time of hold triggers, the maximum time between
+	// the taps of double tap triggers, and the delay before repeat triggers
+	// start repeating, in seconds.
+	Duration float32 `json:"duration,omitempty"`
+	// Interval is the time between the repeats of repeat triggers, in
+	// seconds.
+	Interval float32 `json:"interval,omitempty"`
+}
+
+// Validate returns an error if the trigger can't work, e.g. an axis trigger
+// without a threshold, which has no direction.
+func (t Trigger) Validate() error {
+	isAxis := t.InputType == InputTypeGamepadAxis || t.InputType == InputTypeMouseWheel
+	if isAxis && t.AxisThreshold == 0 {
+		return errors.New("axis triggers need a threshold other than 0")
+	}
+	return nil
+}
+
+// IsAxisActive returns true if the axis value reaches the threshold of the
+// trigger. A threshold of 0 is never reached.
+func (t Trigger) IsAxisActive(value float32) bool {
+	if t.AxisThreshold == 0 {
+		return false
+	}
+	if t.AxisThreshold < 0 {
+		return value <= t.AxisThreshold
+	}
+	return value >= t.AxisThreshold
+}
+
+// AxisStrength returns how far the axis value points in the direction of the
+// trigger's threshold, which is 0 for the opposite direction.
+func (t Trigger) AxisStrength(value float32) float32 {
+	if t.AxisThreshold < 0 {
+		value = -value
+	}
+	return max(value, 0)
+}
+
+// names used for the types in bindings files.
+var (
+	triggerTypeNames = []string{"down", "pressed", "released", "passive", "hold", "double_tap", "repeat"}
+	inputTypeNames   = []string{"key", "mouse", "gamepad", "gamepad_axis", "mouse_wheel"}
+	modifierNames    = []string{"shift", "ctrl", "alt", "super"}
+)
+
+func (t TriggerType) MarshalText() ([]byte, error) {
+	return marshalName(triggerTypeNames, int(t))
+}
+
+func (t *TriggerType) UnmarshalText(text []byte) error {
+	return unmarshalName(triggerTypeNames, text, (*int32)(t))
+}
+
+func (t InputType) MarshalText() ([]byte, error) {
+	return marshalName(inputTypeNames, int(t))
+}
+
+func (t *InputType) UnmarshalText(text []byte) error {
+	return unmarshalName(inputTypeNames, text, (*int32)(t))
+}
+
+// MarshalText writes the modifiers as names joined by "+", e.g. "shift+ctrl".
+func (m Modifiers) MarshalText() ([]byte, error) {
+	names := []string{}
+	for i, name := range modifierNames {
+		if m&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return []byte(strings.Join(names, "+")), nil
+}
+
+func (m *Modifiers) UnmarshalText(text []byte) error {
+	*m = 0
+	if len(text) == 0 {
+		return nil
+	}
+	for _, name := range strings.Split(string(text), "+") {
+		var i int32
+		if err := unmarshalName(modifierNames, []byte(name), &i); err != nil {
+			return err
+		}
+		*m |= 1 << i
+	}
+	return nil
+}
+
+func marshalName(names []string, value int) ([]byte, error) {
+	if value < 0 || value >= len(names) {
+		return nil, fmt.Errorf("unknown value %v", value)
+	}
+	return []byte(names[value]), nil
+}
+
+func unmarshalName(names []string, text []byte, value *int32) error {
+	for i, name := range names {
+		if name == string(text) {
+			*value = int32(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown name %q", text)
 }
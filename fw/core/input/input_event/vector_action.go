@@ -0,0 +1,37 @@
+package input
+
+import "slices"
+
+// VectorAction combines four directional actions into a 2D vector, e.g. WASD
+// or a stick into a movement direction.
+type VectorAction struct {
+	Action               Action
+	NegativeX, PositiveX Action
+	NegativeY, PositiveY Action
+}
+
+var vectorActions = []VectorAction{}
+
+// RegisterVectorAction registers an action whose value is a vector built from
+// the strengths of four other actions: x = positiveX - negativeX and
+// y = positiveY - negativeY. The vector is shortened to a length of 1 if
+// needed, so diagonals are not faster. Registering an action again replaces
+// its components.
+func RegisterVectorAction(action, negativeX, positiveX, negativeY, positiveY Action) {
+	vectorActions = slices.DeleteFunc(vectorActions, func(v VectorAction) bool {
+		return v.Action == action
+	})
+	vectorActions = append(vectorActions, VectorAction{
+		Action:    action,
+		NegativeX: negativeX,
+		PositiveX: positiveX,
+		NegativeY: negativeY,
+		PositiveY: positiveY,
+	})
+}
+
+// GetVectorActions returns all registered vector actions, in registration
+// order.
+func GetVectorActions() []VectorAction {
+	return slices.Clone(vectorActions)
+}
@@ -0,0 +1,139 @@
+package input
+
+import (
+	"slices"
+
+	input "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// playerAction identifies an action performed by a specific player.
+type playerAction struct {
+	player int
+	action input.Action
+}
+
+// actionState holds the strength of every action performed in the last
+// polled frame, so it can be polled instead of waiting for events. It is built from
+// the events of the input source, so polling also works with sources that do
+// not read any device.
+var actionState = &frameState{strengths: make(map[playerAction]float32)}
+
+type frameState struct {
+	strengths map[playerAction]float32
+	vectors   map[playerAction]rl.Vector2
+	// inputTypes holds the input type of the strongest event of an action.
+	inputTypes map[playerAction]input.InputType
+	// cursor is the cursor position of the last frame that had events.
+	cursor rl.Vector2
+}
+
+// update replaces the state with the given events of a new frame. It returns
+// an event for every vector action that is performed, carrying its vector.
+func (s *frameState) update(events []*input.InputEvent) []*input.InputEvent {
+	s.strengths = make(map[playerAction]float32)
+	s.vectors = make(map[playerAction]rl.Vector2)
+	s.inputTypes = make(map[playerAction]input.InputType)
+	if len(events) > 0 {
+		s.cursor = events[0].GetScreenSpaceMousePosition()
+	}
+	players := []int{}
+	for _, event := range events {
+		key := playerAction{player: event.Player, action: event.Action}
+		if strength, ok := s.strengths[key]; !ok || event.Strength > strength {
+			s.strengths[key] = event.Strength
+			s.inputTypes[key] = event.InputType
+		}
+		if !slices.Contains(players, event.Player) {
+			players = append(players, event.Player)
+		}
+	}
+
+	vectorEvents := []*input.InputEvent{}
+	for _, vectorAction := range input.GetVectorActions() {
+		if !IsActionEnabled(vectorAction.Action) {
+			continue
+		}
+		for _, player := range players {
+			vector := s.combine(player, vectorAction)
+			if vector.X == 0 && vector.Y == 0 {
+				continue
+			}
+			key := playerAction{player: player, action: vectorAction.Action}
+			s.vectors[key] = vector
+			s.strengths[key] = rl.Vector2Length(vector)
+
+			event := input.NewGamepadInputEvent(vectorAction.Action, s.cursor, input.NoGamepad, player)
+			event.Strength = s.strengths[key]
+			event.Axis = vector
+			event.InputType = s.strongestInputType(player, vectorAction)
+			vectorEvents = append(vectorEvents, event)
+		}
+	}
+	return vectorEvents
+}
+
+// strongestInputType returns the input type of the strongest component of a
+// vector action.
+func (s *frameState) strongestInputType(player int, vectorAction input.VectorAction) input.InputType {
+	var strongest playerAction
+	for _, action := range []input.Action{vectorAction.NegativeX, vectorAction.PositiveX, vectorAction.NegativeY, vectorAction.PositiveY} {
+		key := playerAction{player: player, action: action}
+		if s.strengths[key] > s.strengths[strongest] {
+			strongest = key
+		}
+	}
+	return s.inputTypes[strongest]
+}
+
+// combine builds the vector of a vector action from the strengths of its
+// components, shortened to a length of at most 1.
+func (s *frameState) combine(player int, vectorAction input.VectorAction) rl.Vector2 {
+	strength := func(action input.Action) float32 {
+		return s.strengths[playerAction{player: player, action: action}]
+	}
+	vector := rl.NewVector2(
+		strength(vectorAction.PositiveX)-strength(vectorAction.NegativeX),
+		strength(vectorAction.PositiveY)-strength(vectorAction.NegativeY),
+	)
+	if rl.Vector2Length(vector) > 1 {
+		vector = rl.Vector2Normalize(vector)
+	}
+	return vector
+}
+
+// GetActionStrength returns how much player 0 performs the action in the
+// current frame, from 0 to 1. Keyboard and mouse always belong to player 0.
+func GetActionStrength(action input.Action) float32 {
+	return GetPlayerActionStrength(0, action)
+}
+
+// GetPlayerActionStrength returns how much the player performs the action in
+// the current frame, from 0 to 1.
+func GetPlayerActionStrength(player int, action input.Action) float32 {
+	return actionState.strengths[playerAction{player: player, action: action}]
+}
+
+// IsActionActive returns true if player 0 performs the action in the current
+// frame.
+func IsActionActive(action input.Action) bool {
+	return GetActionStrength(action) > 0
+}
+
+// GetVector returns the vector of a vector action for player 0, e.g.
+// GetVector(input.ActionMove). See input.RegisterVectorAction.
+func GetVector(action input.Action) rl.Vector2 {
+	return GetPlayerVector(0, action)
+}
+
+// GetPlayerVector returns the vector of a vector action for the player.
+func GetPlayerVector(player int, action input.Action) rl.Vector2 {
+	return actionState.vectors[playerAction{player: player, action: action}]
+}
+
+// GetCursorPosition returns the screen position of the cursor in the last
+// polled frame.
+func GetCursorPosition() rl.Vector2 {
+	return actionState.cursor
+}
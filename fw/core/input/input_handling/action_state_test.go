@@ -0,0 +1,57 @@
+package input
+
+import (
+	"testing"
+
+	input "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestVectorActionFromEvents(t *testing.T) {
+	source := NewQueuedInputSource()
+	SetInputSource(source)
+	defer SetInputSource(nil)
+
+	receiver := &eventLog{}
+
+	// keyboard diagonal, the vector is normalized.
+	source.Queue(
+		input.NewInputEvent(input.ActionMoveRight, rl.Vector2{}),
+		input.NewInputEvent(input.ActionMoveUp, rl.Vector2{}),
+	)
+	HandleInputEvents([]InputReceiver{receiver}, nil)
+	vector := GetVector(input.ActionMove)
+	if rl.Vector2Length(vector) < 0.999 || vector.X <= 0 || vector.Y >= 0 {
+		t.Errorf("expected a normalized up-right vector, got %v", vector)
+	}
+	last := receiver.events[len(receiver.events)-1]
+	if last.Action != input.ActionMove || last.Axis != vector {
+		t.Errorf("expected a move event carrying the vector, got %v", last)
+	}
+
+	// half tilted stick of player 1, player 0 is idle.
+	stick := input.NewGamepadInputEvent(input.ActionMoveLeft, rl.Vector2{}, 1, 1)
+	stick.Strength = 0.5
+	source.Queue(stick)
+	HandleInputEvents(nil, nil)
+	if got := GetPlayerVector(1, input.ActionMove); got != rl.NewVector2(-0.5, 0) {
+		t.Errorf("expected (-0.5, 0) for player 1, got %v", got)
+	}
+	if got := GetPlayerActionStrength(1, input.ActionMoveLeft); got != 0.5 {
+		t.Errorf("expected strength 0.5, got %v", got)
+	}
+	if IsActionActive(input.ActionMove) {
+		t.Error("expected player 0 to be idle")
+	}
+}
+
+// eventLog keeps all events it received.
+type eventLog struct {
+	events []*input.InputEvent
+}
+
+func (l *eventLog) OnInputEvent(event *input.InputEvent) bool {
+	l.events = append(l.events, event)
+	return true
+}
@@ -0,0 +1,132 @@
+package input
+
+import (
+	input "gorl/fw/core/input/input_event"
+	"gorl/fw/core/logging"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TriggerCapturer is implemented by input sources that can report the raw
+// trigger of the last key or button press, used by ListenForNextInput.
+type TriggerCapturer interface {
+	CaptureTrigger() (input.Trigger, bool)
+}
+
+// captureThreshold is the axis value a gamepad axis has to cross to be
+// captured, and the threshold of the captured trigger.
+const captureThreshold = 0.5
+
+// onCapture is the callback waiting for the next input, if any.
+var onCapture func(trigger input.Trigger)
+
+// captureAxes holds the gamepad axis values of the previous frame while
+// listening, so only axes that move are captured, not sticks at rest.
+var captureAxes = make(map[gamepadAxis]float32)
+
+// gamepadAxis is an axis of a gamepad.
+type gamepadAxis struct {
+	gamepad, axis int32
+}
+
+// ListenForNextInput captures the next key, mouse button, gamepad button or
+// gamepad axis movement, e.g. to let the player choose a new binding. The callback receives a pressed
+// trigger for it, which can be passed to input.Bind or input.Rebind. While
+// listening, no input events are sent to the entities.
+//
+// The input source has to implement TriggerCapturer. Otherwise, an error is
+// logged and listening stops on the next frame, e.g. during a replay.
+func ListenForNextInput(callback func(trigger input.Trigger)) {
+	onCapture = callback
+	clear(captureAxes)
+}
+
+// CancelListening stops waiting for the next input without calling the
+// callback passed to ListenForNextInput.
+func CancelListening() {
+	onCapture = nil
+}
+
+// IsListening returns true while waiting for the next input.
+func IsListening() bool {
+	return onCapture != nil
+}
+
+// captureNextInput checks for a captured trigger and calls the callback. It
+// returns true while input is being captured.
+func captureNextInput() bool {
+	if onCapture == nil {
+		return false
+	}
+	capturer, ok := currentSource.(TriggerCapturer)
+	if !ok {
+		// waiting for a trigger that never comes would block all input.
+		logging.Error("Input source %T can't capture triggers, stopped listening for the next input", currentSource)
+		onCapture = nil
+		return false
+	}
+	if trigger, ok := capturer.CaptureTrigger(); ok {
+		callback := onCapture
+		onCapture = nil
+		callback(trigger)
+	}
+	return true
+}
+
+func (raylibInputSource) CaptureTrigger() (input.Trigger, bool) {
+	// modifier keys are not captured on their own, but with the next key.
+	for key := rl.GetKeyPressed(); key != 0; key = rl.GetKeyPressed() {
+		if !isModifierKey(key) {
+			return input.Trigger{InputType: input.InputTypeKey, TriggerType: input.TriggerTypePressed, Key: key, Modifiers: getModifiersDown()}, true
+		}
+	}
+	for button := int32(rl.MouseButtonLeft); button <= rl.MouseButtonBack; button++ {
+		if rl.IsMouseButtonPressed(button) {
+			return input.Trigger{InputType: input.InputTypeMouse, TriggerType: input.TriggerTypePressed, MouseButton: button}, true
+		}
+	}
+	if gamepads.enabled {
+		return captureGamepadTrigger()
+	}
+	return input.Trigger{}, false
+}
+
+// captureGamepadTrigger returns a trigger for the first gamepad button that
+// was pressed, or the first gamepad axis that crossed the capture threshold.
+func captureGamepadTrigger() (input.Trigger, bool) {
+	for gamepad := int32(0); gamepad < MaxGamepads; gamepad++ {
+		if !rl.IsGamepadAvailable(gamepad) {
+			continue
+		}
+		for button := int32(rl.GamepadButtonLeftFaceUp); button <= rl.GamepadButtonRightThumb; button++ {
+			if rl.IsGamepadButtonPressed(gamepad, button) {
+				return input.Trigger{InputType: input.InputTypeGamepad, TriggerType: input.TriggerTypePressed, GamepadButton: button}, true
+			}
+		}
+		for axis := int32(rl.GamepadAxisLeftX); axis <= rl.GamepadAxisRightTrigger; axis++ {
+			key := gamepadAxis{gamepad: gamepad, axis: axis}
+			value := GetGamepadAxis(gamepad, axis)
+			last, seen := captureAxes[key]
+			captureAxes[key] = value
+			if !seen {
+				continue
+			}
+			if threshold, ok := axisCrossing(last, value); ok {
+				return input.Trigger{InputType: input.InputTypeGamepadAxis, TriggerType: input.TriggerTypeDown, GamepadAxis: axis, AxisThreshold: threshold}, true
+			}
+		}
+	}
+	return input.Trigger{}, false
+}
+
+// axisCrossing returns the threshold the axis crossed between two frames, in
+// the direction it moved.
+func axisCrossing(last, value float32) (float32, bool) {
+	switch {
+	case last < captureThreshold && value >= captureThreshold:
+		return captureThreshold, true
+	case last > -captureThreshold && value <= -captureThreshold:
+		return -captureThreshold, true
+	}
+	return 0, false
+}
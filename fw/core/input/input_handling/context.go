@@ -0,0 +1,112 @@
+package input
+
+import (
+	"slices"
+
+	input "gorl/fw/core/input/input_event"
+	"gorl/fw/core/logging"
+)
+
+// contexts holds the registered input contexts and the pushed ones. An input
+// context enables a subset of the actions, e.g. "gameplay", "menu" or "text
+// entry". Only the actions of the context on top of the stack produce
+// events, all actions do while the stack is empty.
+var contexts = struct {
+	registered map[string][]input.Action
+	stack      []string
+}{
+	registered: make(map[string][]input.Action),
+}
+
+// RegisterContext registers an input context enabling the given actions.
+// Registering a context again replaces its actions.
+func RegisterContext(name string, actions ...input.Action) {
+	contexts.registered[name] = slices.Clone(actions)
+}
+
+// PushContext makes the context the active one, until it is popped again.
+func PushContext(name string) {
+	if _, ok := contexts.registered[name]; !ok {
+		logging.Error("Input context \"%v\" is not registered.", name)
+		return
+	}
+	contexts.stack = append(contexts.stack, name)
+}
+
+// PopContext deactivates the active context, and activates the one below it.
+func PopContext() {
+	if len(contexts.stack) == 0 {
+		logging.Warning("Input context stack is empty, nothing to pop.")
+		return
+	}
+	contexts.stack = contexts.stack[:len(contexts.stack)-1]
+}
+
+// GetContextStack returns the names of the pushed contexts, from bottom to
+// top.
+func GetContextStack() []string {
+	return slices.Clone(contexts.stack)
+}
+
+// IsActionEnabled returns true if the action is enabled by the active
+// context.
+func IsActionEnabled(action input.Action) bool {
+	if len(contexts.stack) == 0 {
+		return true
+	}
+	top := contexts.stack[len(contexts.stack)-1]
+	return slices.Contains(contexts.registered[top], action)
+}
+
+// focused is the receiver that grabbed the input, if any.
+var focused InputReceiver
+
+// GrabFocus sends all input events exclusively to the receiver, e.g. a text
+// field or an entity being dragged, until ReleaseFocus is called. Entities
+// holding the focus should release it in OnExitTree.
+func GrabFocus(receiver InputReceiver) {
+	focused = receiver
+}
+
+// ReleaseFocus gives up the focus, if the receiver holds it.
+func ReleaseFocus(receiver InputReceiver) {
+	if focused == receiver {
+		focused = nil
+	}
+}
+
+// GetFocus returns the receiver holding the focus, or nil.
+func GetFocus() InputReceiver {
+	return focused
+}
+
+// HasFocus returns true if the receiver holds the focus.
+func HasFocus(receiver InputReceiver) bool {
+	return focused != nil && focused == receiver
+}
+
+// mouseConsumed is true if the mouse input of the current frame was already
+// handled, see ConsumeMouse.
+var mouseConsumed bool
+
+// ConsumeMouse marks the mouse input of the current frame as handled, e.g.
+// by a gui widget under the cursor. Events caused by mouse buttons or the
+// wheel are then not sent to the entities.
+func ConsumeMouse() {
+	mouseConsumed = true
+}
+
+// filterEvents drops the events of actions that are not enabled by the
+// active context, and mouse events if the mouse was consumed.
+func filterEvents(events []*input.InputEvent) []*input.InputEvent {
+	return slices.DeleteFunc(events, func(event *input.InputEvent) bool {
+		if mouseConsumed && isMouseInput(event.InputType) {
+			return true
+		}
+		return !IsActionEnabled(event.Action)
+	})
+}
+
+func isMouseInput(inputType input.InputType) bool {
+	return inputType == input.InputTypeMouse || inputType == input.InputTypeMouseWheel
+}
@@ -0,0 +1,61 @@
+package input
+
+import (
+	"testing"
+
+	input "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// countingReceiver counts the events it received per action.
+type countingReceiver struct {
+	counts map[input.Action]int
+}
+
+func newCountingReceiver() *countingReceiver {
+	return &countingReceiver{counts: make(map[input.Action]int)}
+}
+
+func (r *countingReceiver) OnInputEvent(event *input.InputEvent) bool {
+	r.counts[event.Action]++
+	return true
+}
+
+func TestContextsAndFocus(t *testing.T) {
+	source := NewQueuedInputSource()
+	SetInputSource(source)
+	defer SetInputSource(nil)
+
+	click := input.NewInputEvent(input.ActionClickDown, rl.Vector2{})
+	click.InputType = input.InputTypeMouse
+	queueFrame := func() {
+		source.Queue(input.NewInputEvent(input.ActionMoveUp, rl.Vector2{}), input.NewInputEvent(input.ActionEscape, rl.Vector2{}), click)
+	}
+
+	world, menu := newCountingReceiver(), newCountingReceiver()
+	RegisterContext("menu", input.ActionEscape, input.ActionClickDown)
+	PushContext("menu")
+	queueFrame()
+	HandleInputEvents([]InputReceiver{world, menu}, nil)
+	if world.counts[input.ActionMoveUp] != 0 || world.counts[input.ActionEscape] != 1 || IsActionActive(input.ActionMoveUp) {
+		t.Errorf("expected only the menu actions, got %v", world.counts)
+	}
+
+	// the gui handled the click, the focused menu gets the rest.
+	GrabFocus(menu)
+	ConsumeMouse()
+	queueFrame()
+	HandleInputEvents([]InputReceiver{world, menu}, nil)
+	if world.counts[input.ActionEscape] != 1 || menu.counts[input.ActionEscape] != 2 || menu.counts[input.ActionClickDown] != 1 {
+		t.Errorf("expected the escape to reach only the focus and the click to be consumed, got %v and %v", world.counts, menu.counts)
+	}
+
+	ReleaseFocus(menu)
+	PopContext()
+	queueFrame()
+	HandleInputEvents([]InputReceiver{world}, nil)
+	if world.counts[input.ActionMoveUp] != 1 || world.counts[input.ActionClickDown] != 2 {
+		t.Errorf("expected all actions after popping the context, got %v", world.counts)
+	}
+}
@@ -0,0 +1,171 @@
+package input
+
+import (
+	"math"
+
+	input "gorl/fw/core/input/input_event"
+	"gorl/fw/core/logging"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// MaxGamepads is the number of gamepads that are checked for input.
+const MaxGamepads = 4
+
+// gamepads holds the state of the gamepad support.
+var gamepads = struct {
+	enabled   bool
+	deadzone  float32
+	connected [MaxGamepads]bool
+	players   [MaxGamepads]int
+
+	// axisActive holds the axis triggers that reached their threshold in the
+	// previous frame.
+	axisActive map[deviceTrigger]bool
+}{
+	deadzone:   0.2,
+	players:    [MaxGamepads]int{0, 1, 2, 3},
+	axisActive: make(map[deviceTrigger]bool),
+}
+
+// SetGamepadEnabled enables or disables reading gamepads, see
+// settings.EnableGamepad. Gamepads are disabled by default.
+func SetGamepadEnabled(enabled bool) {
+	gamepads.enabled = enabled
+}
+
+// IsGamepadEnabled returns true if gamepads are read.
+func IsGamepadEnabled() bool {
+	return gamepads.enabled
+}
+
+// SetGamepadDeadzone sets the share of the axis range around the rest
+// position that is read as zero. Values outside the deadzone are rescaled,
+// so they still cover the full range.
+func SetGamepadDeadzone(deadzone float32) {
+	gamepads.deadzone = deadzone
+}
+
+// AssignGamepad routes the events of a gamepad to a player. By default, each
+// gamepad belongs to the player with the same index.
+func AssignGamepad(gamepad int32, player int) {
+	if !isGamepadIndex(gamepad) {
+		logging.Error("Gamepad %v does not exist, only %v gamepads are supported", gamepad, MaxGamepads)
+		return
+	}
+	gamepads.players[gamepad] = player
+}
+
+// GetGamepadPlayer returns the player the gamepad is routed to, or -1 if the
+// gamepad does not exist.
+func GetGamepadPlayer(gamepad int32) int {
+	if !isGamepadIndex(gamepad) {
+		return -1
+	}
+	return gamepads.players[gamepad]
+}
+
+// IsGamepadConnected returns true if the gamepad was connected in the last
+// frame.
+func IsGamepadConnected(gamepad int32) bool {
+	return isGamepadIndex(gamepad) && gamepads.connected[gamepad]
+}
+
+// isGamepadIndex returns true if the gamepad is one of the MaxGamepads
+// gamepads that are checked.
+func isGamepadIndex(gamepad int32) bool {
+	return gamepad >= 0 && gamepad < MaxGamepads
+}
+
+// GetGamepadAxis returns the value of a gamepad axis, with the deadzone
+// applied.
+func GetGamepadAxis(gamepad, axis int32) float32 {
+	return applyDeadzone(rl.GetGamepadAxisMovement(gamepad, axis), gamepads.deadzone)
+}
+
+// applyDeadzone maps values within the deadzone to zero and rescales the
+// remaining range to [-1, 1].
+func applyDeadzone(value, deadzone float32) float32 {
+	magnitude := float32(math.Abs(float64(value)))
+	if magnitude <= deadzone {
+		return 0
+	}
+	scaled := min((magnitude-deadzone)/(1-deadzone), 1)
+	if value < 0 {
+		return -scaled
+	}
+	return scaled
+}
+
+// checkGamepadConnections produces an event for every gamepad that was
+// connected or disconnected since the last frame.
+func checkGamepadConnections(cursorPosition rl.Vector2) []*input.InputEvent {
+	events := []*input.InputEvent{}
+	for gamepad := int32(0); gamepad < MaxGamepads; gamepad++ {
+		connected := rl.IsGamepadAvailable(gamepad)
+		if connected == gamepads.connected[gamepad] {
+			continue
+		}
+		gamepads.connected[gamepad] = connected
+		for key := range gamepads.axisActive {
+			if key.gamepad == gamepad {
+				delete(gamepads.axisActive, key)
+			}
+		}
+		resetTimedTriggers(gamepad)
+		action := input.ActionGamepadDisconnected
+		if connected {
+			action = input.ActionGamepadConnected
+		}
+		event := input.NewGamepadInputEvent(action, cursorPosition, gamepad, gamepads.players[gamepad])
+		event.InputType = input.InputTypeGamepad
+		events = append(events, event)
+	}
+	return events
+}
+
+// checkGamepadTrigger returns the gamepads on which the trigger fired.
+func checkGamepadTrigger(trigger input.Trigger) []firing {
+	fired := []firing{}
+	for gamepad := int32(0); gamepad < MaxGamepads; gamepad++ {
+		if !gamepads.connected[gamepad] {
+			continue
+		}
+
+		var isFired bool
+		strength := float32(1)
+		switch trigger.InputType {
+		case input.InputTypeGamepad:
+			isFired = checkButtonTrigger(gamepad, trigger)
+		case input.InputTypeGamepadAxis:
+			value := GetGamepadAxis(gamepad, trigger.GamepadAxis)
+			isFired = checkAxisTrigger(gamepad, trigger, value)
+			strength = min(trigger.AxisStrength(value), 1)
+		}
+
+		if isFired {
+			fired = append(fired, firing{gamepad: gamepad, strength: strength})
+		}
+	}
+	return fired
+}
+
+// checkAxisTrigger returns true if the axis trigger fired, given the current
+// axis value. It remembers whether the threshold was reached, so pressed and
+// released triggers fire only once.
+func checkAxisTrigger(gamepad int32, trigger input.Trigger, value float32) bool {
+	key := deviceTrigger{gamepad: gamepad, trigger: trigger}
+	wasActive := gamepads.axisActive[key]
+	isActive := trigger.IsAxisActive(value)
+	gamepads.axisActive[key] = isActive
+
+	switch trigger.TriggerType {
+	case input.TriggerTypeDown:
+		return isActive
+	case input.TriggerTypePressed:
+		return isActive && !wasActive
+	case input.TriggerTypeReleased:
+		return !isActive && wasActive
+	}
+	return checkTimedTrigger(gamepad, trigger, isActive)
+}
@@ -0,0 +1,72 @@
+package input
+
+import (
+	"math"
+	"testing"
+
+	input "gorl/fw/core/input/input_event"
+	"gorl/fw/core/logging"
+)
+
+func TestApplyDeadzone(t *testing.T) {
+	cases := []struct{ value, expected float32 }{
+		{0.1, 0}, {-0.2, 0}, {0.6, 0.5}, {-1, -1}, {1.2, 1},
+	}
+	for _, c := range cases {
+		if got := applyDeadzone(c.value, 0.2); math.Abs(float64(got-c.expected)) > 1e-6 {
+			t.Errorf("applyDeadzone(%v) = %v, want %v", c.value, got, c.expected)
+		}
+	}
+}
+
+func TestAxisTriggerEdges(t *testing.T) {
+	pressed := input.Trigger{InputType: input.InputTypeGamepadAxis, TriggerType: input.TriggerTypePressed, AxisThreshold: -0.5}
+	released := pressed
+	released.TriggerType = input.TriggerTypeReleased
+
+	values := []float32{0, -0.7, -0.9, -0.2}
+	expectPressed := []bool{false, true, false, false}
+	expectReleased := []bool{false, false, false, true}
+	for i, value := range values {
+		if got := checkAxisTrigger(0, pressed, value); got != expectPressed[i] {
+			t.Errorf("pressed trigger at %v: got %v, want %v", value, got, expectPressed[i])
+		}
+		if got := checkAxisTrigger(0, released, value); got != expectReleased[i] {
+			t.Errorf("released trigger at %v: got %v, want %v", value, got, expectReleased[i])
+		}
+	}
+}
+
+func TestGamepadIndexesAreChecked(t *testing.T) {
+	logging.Init(t.TempDir())
+	defer AssignGamepad(1, 1)
+
+	AssignGamepad(1, 0)
+	AssignGamepad(MaxGamepads, 0)
+	AssignGamepad(-1, 0)
+	if GetGamepadPlayer(1) != 0 || GetGamepadPlayer(MaxGamepads) != -1 || GetGamepadPlayer(-1) != -1 {
+		t.Errorf("unexpected players %v, %v, %v", GetGamepadPlayer(1), GetGamepadPlayer(MaxGamepads), GetGamepadPlayer(-1))
+	}
+	if IsGamepadConnected(MaxGamepads) || IsGamepadConnected(-1) {
+		t.Errorf("gamepads that don't exist should not be connected")
+	}
+}
+
+func TestAxisCrossing(t *testing.T) {
+	tests := []struct {
+		last, value, threshold float32
+		ok                     bool
+	}{
+		{0, 0.7, captureThreshold, true},
+		{0.1, -1, -captureThreshold, true},
+		{0.7, 0.9, 0, false},
+		{-1, -1, 0, false}, // a trigger at rest
+		{0.2, 0.4, 0, false},
+	}
+	for _, test := range tests {
+		threshold, ok := axisCrossing(test.last, test.value)
+		if threshold != test.threshold || ok != test.ok {
+			t.Errorf("axisCrossing(%v, %v) = %v, %v, want %v, %v", test.last, test.value, threshold, ok, test.threshold, test.ok)
+		}
+	}
+}
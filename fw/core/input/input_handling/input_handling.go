@@ -1,27 +1,86 @@
 package input
 
 import (
+	"slices"
+
 	input "gorl/fw/core/input/input_event"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 // InputReceiver is an interface that should be implemented by any object that
-// is intended to receive input events.
+// is intended to receive input events. Receivers must be comparable, which
+// pointers to entities are.
 type InputReceiver interface {
 	OnInputEvent(event *input.InputEvent) bool
 }
 
-// HandleInputEvents checks for input events and propagates them to the entities.
-// Receives a sorted slice of layers, each containing a slice of entities.
-// Both must be sorted from back to front (from far away to close to camera).
-func HandleInputEvents(inputReceivers []InputReceiver) {
-	// TODO: since therea re no more layers, ths makes no sense. rewrite.
-	events := checkForInputs()
+// InputSource produces the input events of a single frame. The default source
+// polls the raylib window, headless runs swap in their own source.
+type InputSource interface {
+	PollInputEvents() []*input.InputEvent
+}
+
+// raylibInputSource polls the raylib window for input events.
+type raylibInputSource struct{}
+
+// NewRaylibInputSource returns an InputSource that polls the raylib window.
+func NewRaylibInputSource() InputSource {
+	return raylibInputSource{}
+}
+
+func (raylibInputSource) PollInputEvents() []*input.InputEvent {
+	return checkForInputs()
+}
+
+// currentSource is the input source HandleInputEvents reads from.
+var currentSource InputSource = raylibInputSource{}
+
+// SetInputSource replaces the source input events are read from.
+// Passing nil restores the default raylib source.
+func SetInputSource(source InputSource) {
+	if source == nil {
+		source = raylibInputSource{}
+	}
+	currentSource = source
+}
+
+// DrawnReceiver is an input receiver together with the camera that drew it,
+// as returned by render.Draw.
+type DrawnReceiver struct {
+	Receiver InputReceiver
+	Camera   input.Viewport
+}
+
+// HandleInputEvents checks for input events and propagates them to the
+// entities. inputReceivers are the entities that process input, in tree
+// order, and drawn the receivers drawn this frame, back to front, see
+// render.Draw.
+//
+// Each receiver gets an event through the camera that drew it, with the
+// cursor in that camera's world. Mouse events only reach the receivers drawn
+// by the front-most camera under the cursor, and receivers that were not
+// drawn at all. Front-most receivers get events first.
+//
+// Only the actions enabled by the active input context are sent, and only to
+// the receiver holding the focus if there is one.
+func HandleInputEvents(inputReceivers []InputReceiver, drawn []DrawnReceiver) {
+	defer func() { mouseConsumed = false }()
+	if clock, ok := currentSource.(Clock); ok {
+		inputTime += float64(clock.GetFrameTime())
+	}
+	events := currentSource.PollInputEvents()
+	if captureNextInput() {
+		actionState.update(nil)
+		return // the input was meant to choose a binding
+	}
+	events = filterEvents(events)
+	events = append(events, actionState.update(events)...)
+
+	router := newEventRouter(inputReceivers, drawn)
 	for _, event := range events {
-		// walk backwards so that the front-most entities receive the input first
-		for i := len(inputReceivers) - 1; i >= 0; i-- {
-			shouldContinue := inputReceivers[i].OnInputEvent(event)
+		for _, target := range router.route(event) {
+			shouldContinue := target.Receiver.OnInputEvent(event.WithCamera(target.Camera))
 			if !shouldContinue {
 				break
 			}
@@ -29,51 +88,179 @@ func HandleInputEvents(inputReceivers []InputReceiver) {
 	}
 }
 
+// eventRouter decides which receivers get an event, through which camera.
+type eventRouter struct {
+	// receivers are the receivers that process input, front to back.
+	receivers []InputReceiver
+	// drawn holds the processing receivers drawn this frame, front to back.
+	drawn []DrawnReceiver
+	// cameras holds the cameras of each drawn receiver.
+	cameras map[InputReceiver][]input.Viewport
+	// focus is the receiver holding the focus, which gets all events.
+	focus InputReceiver
+}
+
+func newEventRouter(inputReceivers []InputReceiver, drawn []DrawnReceiver) *eventRouter {
+	r := &eventRouter{cameras: make(map[InputReceiver][]input.Viewport), focus: focused}
+	processed := processedReceivers(inputReceivers)
+	for i := len(inputReceivers) - 1; i >= 0; i-- {
+		r.receivers = append(r.receivers, inputReceivers[i])
+	}
+	for i := len(drawn) - 1; i >= 0; i-- {
+		if processed[drawn[i].Receiver] {
+			r.drawn = append(r.drawn, drawn[i])
+			r.cameras[drawn[i].Receiver] = append(r.cameras[drawn[i].Receiver], drawn[i].Camera)
+		}
+	}
+	return r
+}
+
+// GetInputTargets returns the drawn receivers that can get input events,
+// back to front: the ones that process input, or only the receiver holding
+// the focus if there is one. See HandleInputEvents for the arguments.
+func GetInputTargets(inputReceivers []InputReceiver, drawn []DrawnReceiver) []DrawnReceiver {
+	processed := processedReceivers(inputReceivers)
+	targets := []DrawnReceiver{}
+	for _, d := range drawn {
+		if focused != nil && d.Receiver != focused {
+			continue
+		}
+		if processed[d.Receiver] {
+			targets = append(targets, d)
+		}
+	}
+	return targets
+}
+
+// processedReceivers returns the set of receivers that process input,
+// including the focused one.
+func processedReceivers(inputReceivers []InputReceiver) map[InputReceiver]bool {
+	processed := make(map[InputReceiver]bool, len(inputReceivers)+1)
+	if focused != nil {
+		processed[focused] = true
+	}
+	for _, receiver := range inputReceivers {
+		processed[receiver] = true
+	}
+	return processed
+}
+
+// route returns the receivers of the event, front to back, each once.
+func (r *eventRouter) route(event *input.InputEvent) []DrawnReceiver {
+	cursor := event.GetScreenSpaceMousePosition()
+	var hit input.Viewport
+	for _, d := range r.drawn {
+		if d.Camera.ContainsScreenPoint(cursor) {
+			hit = d.Camera
+			break
+		}
+	}
+
+	if r.focus != nil {
+		return []DrawnReceiver{{Receiver: r.focus, Camera: r.focusCamera(hit)}}
+	}
+
+	targets := make([]DrawnReceiver, 0, len(r.receivers))
+	seen := make(map[InputReceiver]bool, len(r.receivers))
+	add := func(receiver InputReceiver, camera input.Viewport) {
+		if !seen[receiver] {
+			seen[receiver] = true
+			targets = append(targets, DrawnReceiver{Receiver: receiver, Camera: camera})
+		}
+	}
+
+	// receivers under the cursor come first, then the ones drawn elsewhere
+	// unless the event is tied to the cursor, then the ones never drawn.
+	for _, d := range r.drawn {
+		if hit != nil && d.Camera == hit {
+			add(d.Receiver, d.Camera)
+		}
+	}
+	if !isMouseInput(event.InputType) {
+		for _, d := range r.drawn {
+			add(d.Receiver, d.Camera)
+		}
+	}
+	for _, receiver := range r.receivers {
+		if len(r.cameras[receiver]) == 0 {
+			add(receiver, nil)
+		}
+	}
+	return targets
+}
+
+// focusCamera returns the camera the focused receiver gets events through,
+// preferring the one under the cursor.
+func (r *eventRouter) focusCamera(hit input.Viewport) input.Viewport {
+	cameras := r.cameras[r.focus]
+	if len(cameras) == 0 {
+		return nil
+	}
+	if slices.Contains(cameras, hit) {
+		return hit
+	}
+	return cameras[0]
+}
+
 func checkForInputs() []*input.InputEvent {
 
 	events := []*input.InputEvent{}
 	mousePosition := rl.GetMousePosition()
 
-	for action, triggers := range input.ActionMap {
-		for _, trigger := range triggers {
-			switch trigger.InputType {
-			case input.InputTypeKey:
-				switch trigger.TriggerType {
-				case input.TriggerTypeDown:
-					if rl.IsKeyDown(trigger.Key) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				case input.TriggerTypePressed:
-					if rl.IsKeyPressed(trigger.Key) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				case input.TriggerTypeReleased:
-					if rl.IsKeyReleased(trigger.Key) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				}
-			case input.InputTypeMouse:
-				switch trigger.TriggerType {
-				case input.TriggerTypeDown:
-					if rl.IsMouseButtonDown(trigger.MouseButton) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				case input.TriggerTypePressed:
-					if rl.IsMouseButtonPressed(trigger.MouseButton) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				case input.TriggerTypeReleased:
-					if rl.IsMouseButtonReleased(trigger.MouseButton) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				case input.TriggerTypePassive:
-					events = append(events, input.NewInputEvent(action, mousePosition))
-				}
-			case input.InputTypeGamepad:
-				// Implement the checks for gamepad buttons using a similar pattern
+	if gamepads.enabled {
+		events = append(events, checkGamepadConnections(mousePosition)...)
+	}
+
+	bound := []input.Trigger{}
+	for _, action := range input.GetActions() {
+		bound = append(bound, input.GetBindings(action)...)
+	}
+	modifiers := getModifiersDown()
+
+	// triggers are checked once per frame, even if they are used by several
+	// bindings and sequences, so edges and timers advance only once.
+	checked := make(map[input.Trigger][]firing)
+	check := func(trigger input.Trigger) []firing {
+		fired, ok := checked[trigger]
+		if !ok {
+			fired = checkTrigger(trigger)
+			if isShadowed(trigger, bound, modifiers) {
+				fired = nil
+			}
+			checked[trigger] = fired
+		}
+		return fired
+	}
+
+	for _, action := range input.GetActions() {
+		for _, trigger := range input.GetBindings(action) {
+			for _, fired := range check(trigger) {
+				events = append(events, newFiredEvent(action, mousePosition, trigger, fired))
 			}
 		}
 	}
+	for i, sequence := range input.GetSequences() {
+		if len(sequence.Steps) == 0 {
+			continue
+		}
+		last := sequence.Steps[len(sequence.Steps)-1]
+		for _, fired := range checkSequence(i, sequence, check) {
+			events = append(events, newFiredEvent(sequence.Action, mousePosition, last, fired))
+		}
+	}
 
 	return events
 }
+
+// newFiredEvent creates the event of an action caused by a trigger firing.
+func newFiredEvent(action input.Action, cursorPosition rl.Vector2, trigger input.Trigger, fired firing) *input.InputEvent {
+	var event *input.InputEvent
+	if fired.gamepad == input.NoGamepad {
+		event = input.NewInputEvent(action, cursorPosition)
+	} else {
+		event = input.NewGamepadInputEvent(action, cursorPosition, fired.gamepad, gamepads.players[fired.gamepad])
+	}
+	event.Strength = fired.strength
+	event.InputType = trigger.InputType
+	return event
+}
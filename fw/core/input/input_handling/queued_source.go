@@ -0,0 +1,49 @@
+package input
+
+import (
+	input "gorl/fw/core/input/input_event"
+)
+
+var _ InputSource = (*QueuedInputSource)(nil)
+
+// QueuedInputSource is an InputSource that does not read any device. Events
+// are queued up front and handed out on the next poll, which makes it
+// suitable for headless runs and tests.
+type QueuedInputSource struct {
+	queued   []*input.InputEvent
+	triggers []input.Trigger
+}
+
+// NewQueuedInputSource creates a new, empty QueuedInputSource.
+func NewQueuedInputSource() *QueuedInputSource {
+	return &QueuedInputSource{queued: make([]*input.InputEvent, 0)}
+}
+
+// Queue adds events that will be returned by the next call to
+// PollInputEvents.
+func (s *QueuedInputSource) Queue(events ...*input.InputEvent) {
+	s.queued = append(s.queued, events...)
+}
+
+// PollInputEvents returns all queued events and clears the queue.
+func (s *QueuedInputSource) PollInputEvents() []*input.InputEvent {
+	events := s.queued
+	s.queued = make([]*input.InputEvent, 0)
+	return events
+}
+
+// QueueTrigger adds a raw trigger that will be captured while listening for
+// the next input, see ListenForNextInput.
+func (s *QueuedInputSource) QueueTrigger(trigger input.Trigger) {
+	s.triggers = append(s.triggers, trigger)
+}
+
+// CaptureTrigger returns the first queued trigger, if any.
+func (s *QueuedInputSource) CaptureTrigger() (input.Trigger, bool) {
+	if len(s.triggers) == 0 {
+		return input.Trigger{}, false
+	}
+	trigger := s.triggers[0]
+	s.triggers = s.triggers[1:]
+	return trigger, true
+}
@@ -0,0 +1,212 @@
+package input
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	input "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// RecordingFormatVersion is the version of the file written by
+// Recording.Save.
+const RecordingFormatVersion = 1
+
+// Clock reports how much time passed during the current frame. Backends
+// implement it.
+type Clock interface {
+	GetFrameTime() float32
+}
+
+// Recording is the input of a run, frame by frame, together with the frame
+// times. Replaying both reproduces the run.
+type Recording struct {
+	Version int `json:"v"`
+	// FrameTimes holds the duration of every recorded frame in seconds.
+	FrameTimes []float32 `json:"t"`
+	// Frames holds the events of the frames that had any, in frame order.
+	Frames []RecordedFrame `json:"f"`
+}
+
+// RecordedFrame holds the events polled in a single frame.
+type RecordedFrame struct {
+	Frame  int             `json:"i"`
+	Events []RecordedEvent `json:"e"`
+}
+
+// RecordedEvent is an input event as stored in a recording.
+type RecordedEvent struct {
+	Action   input.Action    `json:"a"`
+	Cursor   rl.Vector2      `json:"c"`
+	Gamepad  int32           `json:"g,omitempty"`
+	Player   int             `json:"p,omitempty"`
+	Strength float32         `json:"s"`
+	Axis     rl.Vector2      `json:"x"`
+	Input    input.InputType `json:"d"`
+}
+
+// FrameCount returns the number of recorded frames.
+func (r *Recording) FrameCount() int {
+	return len(r.FrameTimes)
+}
+
+// Save writes the recording to a gzip compressed JSON file.
+func (r *Recording) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	if err := json.NewEncoder(writer).Encode(r); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// LoadRecording reads a recording written by Recording.Save.
+func LoadRecording(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	var recording Recording
+	if err := json.NewDecoder(reader).Decode(&recording); err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	if recording.Version > RecordingFormatVersion {
+		return nil, fmt.Errorf("%v: unsupported format version %v", path, recording.Version)
+	}
+	return &recording, nil
+}
+
+func recordEvent(event *input.InputEvent) RecordedEvent {
+	return RecordedEvent{
+		Action:   event.Action,
+		Cursor:   event.GetScreenSpaceMousePosition(),
+		Gamepad:  event.Gamepad,
+		Player:   event.Player,
+		Strength: event.Strength,
+		Axis:     event.Axis,
+		Input:    event.InputType,
+	}
+}
+
+func (e RecordedEvent) toInputEvent() *input.InputEvent {
+	event := input.NewGamepadInputEvent(e.Action, e.Cursor, e.Gamepad, e.Player)
+	event.Strength = e.Strength
+	event.Axis = e.Axis
+	event.InputType = e.Input
+	return event
+}
+
+var _ InputSource = (*RecordingSource)(nil)
+
+// RecordingSource is an InputSource that passes on the events of another
+// source and records them, along with the frame time of the clock.
+type RecordingSource struct {
+	source    InputSource
+	clock     Clock
+	recording *Recording
+}
+
+// NewRecordingSource creates a source recording the events of source. It
+// expects to be polled exactly once per frame, like HandleInputEvents does.
+func NewRecordingSource(source InputSource, clock Clock) *RecordingSource {
+	return &RecordingSource{
+		source:    source,
+		clock:     clock,
+		recording: &Recording{Version: RecordingFormatVersion},
+	}
+}
+
+// PollInputEvents polls the wrapped source and records the frame.
+func (s *RecordingSource) PollInputEvents() []*input.InputEvent {
+	events := s.source.PollInputEvents()
+	frame := s.recording.FrameCount()
+	s.recording.FrameTimes = append(s.recording.FrameTimes, s.clock.GetFrameTime())
+	if len(events) > 0 {
+		recorded := RecordedFrame{Frame: frame, Events: make([]RecordedEvent, len(events))}
+		for i, event := range events {
+			recorded.Events[i] = recordEvent(event)
+		}
+		s.recording.Frames = append(s.recording.Frames, recorded)
+	}
+	return events
+}
+
+// CaptureTrigger passes on captured triggers of the wrapped source. Captured
+// triggers are not recorded, so replays of runs that change bindings this
+// way diverge.
+func (s *RecordingSource) CaptureTrigger() (input.Trigger, bool) {
+	if capturer, ok := s.source.(TriggerCapturer); ok {
+		return capturer.CaptureTrigger()
+	}
+	return input.Trigger{}, false
+}
+
+// GetRecording returns the frames recorded so far.
+func (s *RecordingSource) GetRecording() *Recording {
+	return s.recording
+}
+
+var _ InputSource = (*ReplaySource)(nil)
+
+// ReplaySource is an InputSource that plays back a recording. It is also a
+// Clock returning the recorded frame times, so entities see the same frame
+// times as in the recorded run.
+type ReplaySource struct {
+	recording *Recording
+	frame     int
+	next      int
+}
+
+// NewReplaySource creates a source playing back the recording from its
+// first frame. NextFrame must be called at the end of every frame.
+func NewReplaySource(recording *Recording) *ReplaySource {
+	return &ReplaySource{recording: recording}
+}
+
+// PollInputEvents returns the events recorded for the current frame.
+func (s *ReplaySource) PollInputEvents() []*input.InputEvent {
+	events := []*input.InputEvent{}
+	frames := s.recording.Frames
+	for s.next < len(frames) && frames[s.next].Frame <= s.frame {
+		if frames[s.next].Frame == s.frame {
+			for _, recorded := range frames[s.next].Events {
+				events = append(events, recorded.toInputEvent())
+			}
+		}
+		s.next++
+	}
+	return events
+}
+
+// NextFrame advances the replay to the next recorded frame.
+func (s *ReplaySource) NextFrame() {
+	s.frame++
+}
+
+// GetFrameTime returns the recorded duration of the current frame, or 0 once
+// the replay is done.
+func (s *ReplaySource) GetFrameTime() float32 {
+	if s.IsDone() {
+		return 0
+	}
+	return s.recording.FrameTimes[s.frame]
+}
+
+// IsDone returns true once all recorded frames were played back.
+func (s *ReplaySource) IsDone() bool {
+	return s.frame >= s.recording.FrameCount()
+}
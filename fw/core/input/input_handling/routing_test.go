@@ -0,0 +1,67 @@
+package input
+
+import (
+	"testing"
+
+	input "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// fakeViewport covers a rectangle of the screen, and its world is shifted by
+// offset.
+type fakeViewport struct {
+	bounds rl.Rectangle
+	offset rl.Vector2
+}
+
+func (v *fakeViewport) ScreenToWorld(screenPos rl.Vector2) rl.Vector2 {
+	return rl.Vector2Add(rl.Vector2Subtract(screenPos, rl.NewVector2(v.bounds.X, v.bounds.Y)), v.offset)
+}
+
+func (v *fakeViewport) ContainsScreenPoint(screenPos rl.Vector2) bool {
+	return rl.CheckCollisionPointRec(screenPos, v.bounds)
+}
+
+func TestRoutingThroughCameras(t *testing.T) {
+	source := NewQueuedInputSource()
+	SetInputSource(source)
+	defer SetInputSource(nil)
+
+	// split screen, both halves show the world, a minimap on top of the right
+	// half only shows the map.
+	left := &fakeViewport{bounds: rl.NewRectangle(0, 0, 100, 100)}
+	right := &fakeViewport{bounds: rl.NewRectangle(100, 0, 100, 100), offset: rl.NewVector2(1000, 0)}
+	minimap := &fakeViewport{bounds: rl.NewRectangle(150, 0, 50, 50)}
+	world, mapView, logic := &eventLog{}, &eventLog{}, &eventLog{}
+	processed := []InputReceiver{logic, world, mapView}
+	drawn := []DrawnReceiver{{world, left}, {world, right}, {mapView, minimap}}
+
+	click := func(x, y float32) {
+		event := input.NewInputEvent(input.ActionClickDown, rl.NewVector2(x, y))
+		event.InputType = input.InputTypeMouse
+		source.Queue(event)
+		HandleInputEvents(processed, drawn)
+	}
+
+	click(110, 80)
+	if len(world.events) != 1 || world.events[0].GetCamera() != right ||
+		world.events[0].GetWorldMousePosition() != rl.NewVector2(1010, 80) {
+		t.Fatalf("expected a click in the right world, got %v", world.events)
+	}
+	if len(mapView.events) != 0 || len(logic.events) != 1 || logic.events[0].GetCamera() != nil {
+		t.Errorf("expected the click to reach only the world and the undrawn receiver")
+	}
+
+	click(160, 10)
+	if len(world.events) != 1 || len(mapView.events) != 1 || mapView.events[0].GetCamera() != minimap {
+		t.Errorf("expected the minimap to cover the world")
+	}
+
+	// keys reach everyone, the world through the camera under the cursor.
+	source.Queue(input.NewInputEvent(input.ActionEscape, rl.NewVector2(10, 10)))
+	HandleInputEvents(processed, drawn)
+	if len(world.events) != 2 || world.events[1].GetCamera() != left || len(mapView.events) != 2 {
+		t.Errorf("expected the key to reach all receivers")
+	}
+}
@@ -0,0 +1,270 @@
+package input
+
+import (
+	"math"
+
+	input "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// firing is a trigger firing on a device, with the strength it fired with.
+type firing struct {
+	// gamepad is the gamepad the trigger fired on, or input.NoGamepad for
+	// keyboard and mouse.
+	gamepad  int32
+	strength float32
+}
+
+// deviceTrigger identifies a trigger on a specific gamepad, or on keyboard
+// and mouse for input.NoGamepad. Used to remember the state of triggers
+// between frames.
+type deviceTrigger struct {
+	gamepad int32
+	trigger input.Trigger
+}
+
+// inputTime is the time in seconds the input was handled for, measured by
+// the clock of the input source. Timed triggers and sequences use it, so
+// they work the same in replays.
+var inputTime float64
+
+// checkTrigger returns the devices on which the trigger fired this frame.
+func checkTrigger(trigger input.Trigger) []firing {
+	switch trigger.InputType {
+	case input.InputTypeKey, input.InputTypeMouse:
+		if checkButtonTrigger(input.NoGamepad, trigger) {
+			return []firing{{gamepad: input.NoGamepad, strength: 1}}
+		}
+	case input.InputTypeGamepad, input.InputTypeGamepadAxis:
+		if gamepads.enabled {
+			return checkGamepadTrigger(trigger)
+		}
+	case input.InputTypeMouseWheel:
+		// the wheel has no state, only pressed and down triggers make sense.
+		if wheel := rl.GetMouseWheelMove(); trigger.IsAxisActive(wheel) {
+			return []firing{{gamepad: input.NoGamepad, strength: trigger.AxisStrength(wheel)}}
+		}
+	}
+	return nil
+}
+
+// checkButtonTrigger returns true if a key, mouse button or gamepad button
+// trigger fired this frame.
+func checkButtonTrigger(gamepad int32, trigger input.Trigger) bool {
+	if trigger.TriggerType == input.TriggerTypePassive {
+		return true
+	}
+
+	var down, pressed, released bool
+	switch trigger.InputType {
+	case input.InputTypeKey:
+		down = rl.IsKeyDown(trigger.Key)
+		pressed = rl.IsKeyPressed(trigger.Key)
+		released = rl.IsKeyReleased(trigger.Key)
+	case input.InputTypeMouse:
+		down = rl.IsMouseButtonDown(trigger.MouseButton)
+		pressed = rl.IsMouseButtonPressed(trigger.MouseButton)
+		released = rl.IsMouseButtonReleased(trigger.MouseButton)
+	case input.InputTypeGamepad:
+		down = rl.IsGamepadButtonDown(gamepad, trigger.GamepadButton)
+		pressed = rl.IsGamepadButtonPressed(gamepad, trigger.GamepadButton)
+		released = rl.IsGamepadButtonReleased(gamepad, trigger.GamepadButton)
+	}
+	if gamepad == input.NoGamepad && !areModifiersDown(trigger.Modifiers) {
+		down, pressed, released = false, false, false
+	}
+
+	switch trigger.TriggerType {
+	case input.TriggerTypeDown:
+		return down
+	case input.TriggerTypePressed:
+		return pressed
+	case input.TriggerTypeReleased:
+		return released
+	}
+	return checkTimedTrigger(gamepad, trigger, down)
+}
+
+// modifierKeys holds the left and right key of every modifier.
+var modifierKeys = map[input.Modifiers][2]int32{
+	input.ModifierShift: {rl.KeyLeftShift, rl.KeyRightShift},
+	input.ModifierCtrl:  {rl.KeyLeftControl, rl.KeyRightControl},
+	input.ModifierAlt:   {rl.KeyLeftAlt, rl.KeyRightAlt},
+	input.ModifierSuper: {rl.KeyLeftSuper, rl.KeyRightSuper},
+}
+
+// areModifiersDown returns true if all the modifiers are held.
+func areModifiersDown(modifiers input.Modifiers) bool {
+	return getModifiersDown()&modifiers == modifiers
+}
+
+// isShadowed returns true if a bound trigger of the same key or mouse button
+// needs more modifiers than the trigger, and all of them are held. Only the
+// most specific chord fires, so Ctrl+S does not also fire S.
+func isShadowed(trigger input.Trigger, bound []input.Trigger, held input.Modifiers) bool {
+	if trigger.InputType != input.InputTypeKey && trigger.InputType != input.InputTypeMouse {
+		return false
+	}
+	for _, other := range bound {
+		if other.InputType != trigger.InputType || other.Key != trigger.Key || other.MouseButton != trigger.MouseButton {
+			continue
+		}
+		more := other.Modifiers != trigger.Modifiers && other.Modifiers&trigger.Modifiers == trigger.Modifiers
+		if more && held&other.Modifiers == other.Modifiers {
+			return true
+		}
+	}
+	return false
+}
+
+// getModifiersDown returns the modifiers that are held.
+func getModifiersDown() input.Modifiers {
+	var down input.Modifiers
+	for modifier, keys := range modifierKeys {
+		if rl.IsKeyDown(keys[0]) || rl.IsKeyDown(keys[1]) {
+			down |= modifier
+		}
+	}
+	return down
+}
+
+// isModifierKey returns true if the key is one of the modifier keys.
+func isModifierKey(key int32) bool {
+	for _, keys := range modifierKeys {
+		if key == keys[0] || key == keys[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// timedState is the state of a hold, double tap or repeat trigger.
+type timedState struct {
+	down      bool
+	downSince float64
+	// held is true once a hold trigger fired, until it is released.
+	held       bool
+	lastTap    float64
+	nextRepeat float64
+}
+
+var timedTriggers = make(map[deviceTrigger]*timedState)
+
+// checkTimedTrigger returns true if a hold, double tap or repeat trigger
+// fired, given whether its button is down this frame.
+func checkTimedTrigger(gamepad int32, trigger input.Trigger, down bool) bool {
+	key := deviceTrigger{gamepad: gamepad, trigger: trigger}
+	state, ok := timedTriggers[key]
+	if !ok {
+		state = &timedState{lastTap: math.Inf(-1)}
+		timedTriggers[key] = state
+	}
+	pressed := down && !state.down
+	state.down = down
+	if pressed {
+		state.downSince = inputTime
+	}
+
+	switch trigger.TriggerType {
+	case input.TriggerTypeHold:
+		if !down {
+			state.held = false
+			return false
+		}
+		if !state.held && inputTime-state.downSince >= float64(trigger.Duration) {
+			state.held = true
+			return true
+		}
+	case input.TriggerTypeDoubleTap:
+		if !pressed {
+			return false
+		}
+		if inputTime-state.lastTap <= float64(trigger.Duration) {
+			// a third tap starts a new double tap.
+			state.lastTap = math.Inf(-1)
+			return true
+		}
+		state.lastTap = inputTime
+	case input.TriggerTypeRepeat:
+		if pressed {
+			state.nextRepeat = inputTime + float64(trigger.Duration)
+			return true
+		}
+		if down && inputTime >= state.nextRepeat {
+			state.nextRepeat += float64(trigger.Interval)
+			return true
+		}
+	}
+	return false
+}
+
+// resetTimedTriggers forgets the state of the timed triggers of a gamepad.
+func resetTimedTriggers(gamepad int32) {
+	for key := range timedTriggers {
+		if key.gamepad == gamepad {
+			delete(timedTriggers, key)
+		}
+	}
+}
+
+// sequenceKey identifies a registered sequence on a specific device.
+type sequenceKey struct {
+	index   int
+	gamepad int32
+}
+
+// sequenceProgress is how far a sequence was entered on a device.
+type sequenceProgress struct {
+	step     int
+	lastStep float64
+}
+
+var sequenceProgresses = make(map[sequenceKey]*sequenceProgress)
+
+// checkSequence advances the sequence with the index on every device one of
+// its steps fired on, and returns the devices on which it was completed.
+func checkSequence(index int, sequence input.Sequence, check func(input.Trigger) []firing) []firing {
+	// the steps that fired, per device, in the order the devices fired.
+	devices := []int32{}
+	firedSteps := make(map[int32][]bool)
+	for step, trigger := range sequence.Steps {
+		for _, fired := range check(trigger) {
+			if _, ok := firedSteps[fired.gamepad]; !ok {
+				devices = append(devices, fired.gamepad)
+				firedSteps[fired.gamepad] = make([]bool, len(sequence.Steps))
+			}
+			firedSteps[fired.gamepad][step] = true
+		}
+	}
+
+	completed := []firing{}
+	for _, gamepad := range devices {
+		key := sequenceKey{index: index, gamepad: gamepad}
+		progress, ok := sequenceProgresses[key]
+		if !ok {
+			progress = &sequenceProgress{}
+			sequenceProgresses[key] = progress
+		}
+		if progress.step > 0 && inputTime-progress.lastStep > float64(sequence.Window) {
+			progress.step = 0
+		}
+
+		steps := firedSteps[gamepad]
+		switch {
+		case steps[progress.step]:
+			progress.step++
+		case steps[0]:
+			progress.step = 1
+		default:
+			progress.step = 0
+		}
+		progress.lastStep = inputTime
+
+		if progress.step == len(sequence.Steps) {
+			progress.step = 0
+			completed = append(completed, firing{gamepad: gamepad, strength: 1})
+		}
+	}
+	return completed
+}
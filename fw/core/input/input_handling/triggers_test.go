@@ -0,0 +1,124 @@
+package input
+
+import (
+	"testing"
+
+	input "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// runTimedTrigger feeds the down state of every frame to a timed trigger,
+// with frames of 0.1 seconds, and returns the frames on which it fired.
+func runTimedTrigger(trigger input.Trigger, down []bool) []int {
+	inputTime = 0
+	timedTriggers = make(map[deviceTrigger]*timedState)
+	fired := []int{}
+	for frame, isDown := range down {
+		inputTime += 0.1
+		if checkTimedTrigger(input.NoGamepad, trigger, isDown) {
+			fired = append(fired, frame)
+		}
+	}
+	return fired
+}
+
+func TestTimedTriggers(t *testing.T) {
+	cases := []struct {
+		name     string
+		trigger  input.Trigger
+		down     []bool
+		expected []int
+	}{
+		{
+			name:     "hold",
+			trigger:  input.Trigger{TriggerType: input.TriggerTypeHold, Duration: 0.25},
+			down:     []bool{true, true, true, true, true, false, true, true},
+			expected: []int{3},
+		},
+		{
+			name:     "double tap",
+			trigger:  input.Trigger{TriggerType: input.TriggerTypeDoubleTap, Duration: 0.25},
+			down:     []bool{true, false, true, false, false, false, true, false, false, false, true},
+			expected: []int{2},
+		},
+		{
+			name:     "repeat",
+			trigger:  input.Trigger{TriggerType: input.TriggerTypeRepeat, Duration: 0.35, Interval: 0.2},
+			down:     []bool{true, true, true, true, true, true, true, false},
+			expected: []int{0, 4, 6},
+		},
+	}
+	for _, c := range cases {
+		got := runTimedTrigger(c.trigger, c.down)
+		if len(got) != len(c.expected) {
+			t.Errorf("%v: fired on frames %v, want %v", c.name, got, c.expected)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.expected[i] {
+				t.Errorf("%v: fired on frames %v, want %v", c.name, got, c.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	down := input.Trigger{InputType: input.InputTypeKey, TriggerType: input.TriggerTypePressed, Key: rl.KeyS}
+	right := input.Trigger{InputType: input.InputTypeKey, TriggerType: input.TriggerTypePressed, Key: rl.KeyD}
+	punch := input.Trigger{InputType: input.InputTypeKey, TriggerType: input.TriggerTypePressed, Key: rl.KeyJ}
+	sequence := input.Sequence{Action: "fireball", Steps: []input.Trigger{down, right, punch}, Window: 0.3}
+
+	inputTime = 0
+	sequenceProgresses = make(map[sequenceKey]*sequenceProgress)
+	completed := []int{}
+	// each frame lasts 0.1 seconds, nil frames have no input.
+	frames := []*input.Trigger{
+		&down, &right, &punch, // completed
+		&down, &right, nil, nil, nil, &punch, // too slow
+		&down, &punch, &down, &right, &punch, // out of order, then completed
+	}
+	for frame, pressed := range frames {
+		inputTime += 0.1
+		check := func(trigger input.Trigger) []firing {
+			if pressed != nil && *pressed == trigger {
+				return []firing{{gamepad: input.NoGamepad, strength: 1}}
+			}
+			return nil
+		}
+		if len(checkSequence(0, sequence, check)) > 0 {
+			completed = append(completed, frame)
+		}
+	}
+	if len(completed) != 2 || completed[0] != 2 || completed[1] != 13 {
+		t.Errorf("expected the sequence to complete on frames 2 and 13, got %v", completed)
+	}
+}
+
+func TestChordsShadowFewerModifiers(t *testing.T) {
+	s := input.Trigger{InputType: input.InputTypeKey, Key: rl.KeyS}
+	ctrlS := input.Trigger{InputType: input.InputTypeKey, Key: rl.KeyS, Modifiers: input.ModifierCtrl}
+	ctrlShiftS := input.Trigger{InputType: input.InputTypeKey, Key: rl.KeyS, Modifiers: input.ModifierCtrl | input.ModifierShift}
+	w := input.Trigger{InputType: input.InputTypeKey, Key: rl.KeyW}
+	bound := []input.Trigger{s, ctrlS, ctrlShiftS, w}
+
+	cases := []struct {
+		trigger  input.Trigger
+		held     input.Modifiers
+		shadowed bool
+	}{
+		{s, 0, false},
+		{s, input.ModifierCtrl, true},
+		{s, input.ModifierShift, false}, // no Shift+S binding
+		{ctrlS, input.ModifierCtrl, false},
+		{ctrlS, input.ModifierCtrl | input.ModifierShift, true},
+		{ctrlShiftS, input.ModifierCtrl | input.ModifierShift, false},
+		{w, input.ModifierCtrl, false},
+	}
+	for _, c := range cases {
+		if got := isShadowed(c.trigger, bound, c.held); got != c.shadowed {
+			t.Errorf("isShadowed(%+v) with %v held = %v, want %v", c.trigger, c.held, got, c.shadowed)
+		}
+	}
+}
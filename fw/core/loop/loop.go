@@ -0,0 +1,174 @@
+package loop
+
+import (
+	"math"
+	"time"
+
+	"gorl/fw/core/logging"
+)
+
+// Clock reports how much time passed during the last frame. The current
+// backend is the usual clock, tests can inject their own.
+type Clock interface {
+	GetFrameTime() float32
+}
+
+// loop keeps track of simulation time. Every frame, the time reported by the
+// clock is scaled and added to an accumulator, which is then consumed in
+// steps of exactly fixedTimestep. The remainder is exposed as an
+// interpolation alpha, so drawing can blend between the last two fixed
+// states.
+type loop struct {
+	clock         Clock
+	fixedTimestep time.Duration
+	accumulator   time.Duration
+
+	// maxFixedSteps caps the number of fixed steps per frame. If a frame
+	// takes so long that more steps would be needed, the excess time is
+	// dropped instead of spiralling into ever longer frames.
+	maxFixedSteps int
+
+	timeScale      float32
+	paused         bool
+	stepsRequested int
+
+	// per frame results of Tick
+	deltaTime  float32
+	fixedSteps int
+	alpha      float32
+	frameCount uint64
+}
+
+// stepTolerance is how far the accumulator may fall short of a full fixed
+// step and still run it.
+const stepTolerance = time.Microsecond
+
+// loopInstance is the global loop instance.
+var loopInstance loop
+
+// Init initializes the loop with the given fixed timestep (in seconds) and
+// the clock that measures frame times.
+func Init(fixedTimestep float32, clock Clock) {
+	if fixedTimestep <= 0 {
+		logging.Error("Provided fixed timestep must be positive!")
+	}
+	loopInstance = loop{
+		clock:         clock,
+		fixedTimestep: secondsToDuration(fixedTimestep),
+		maxFixedSteps: 8,
+		timeScale:     1,
+	}
+}
+
+// Tick advances the loop by one frame and returns how many fixed updates
+// must run during this frame. This can be zero, one or many, depending on
+// the frame time. Must be called once at the start of every frame.
+func Tick() int {
+	l := &loopInstance
+	l.frameCount++
+	frameTime := secondsToDuration(l.clock.GetFrameTime())
+
+	switch {
+	case l.paused && l.stepsRequested > 0:
+		// a single step ignores the clock and the accumulator entirely.
+		l.stepsRequested--
+		l.deltaTime = durationToSeconds(l.fixedTimestep)
+		l.fixedSteps = 1
+	case l.paused:
+		l.deltaTime = 0
+		l.fixedSteps = 0
+	default:
+		scaled := time.Duration(float64(frameTime) * float64(l.timeScale))
+		l.deltaTime = durationToSeconds(scaled)
+		l.accumulator += scaled
+
+		// frame times are floats, so a frame that should complete a step can
+		// fall a few nanoseconds short. stepTolerance absorbs that, at the
+		// cost of a tiny negative accumulator.
+		l.fixedSteps = int((l.accumulator + stepTolerance) / l.fixedTimestep)
+		l.accumulator -= time.Duration(l.fixedSteps) * l.fixedTimestep
+		if l.fixedSteps > l.maxFixedSteps {
+			l.fixedSteps = l.maxFixedSteps
+		}
+	}
+
+	l.alpha = float32(max(0, float64(l.accumulator)/float64(l.fixedTimestep)))
+	return l.fixedSteps
+}
+
+// GetDeltaTime returns the scaled time of the current frame in seconds.
+// It is zero while the loop is paused.
+func GetDeltaTime() float32 {
+	return loopInstance.deltaTime
+}
+
+// GetFixedTimestep returns the fixed timestep in seconds. FixedUpdate should
+// use this instead of the frame time.
+func GetFixedTimestep() float32 {
+	return durationToSeconds(loopInstance.fixedTimestep)
+}
+
+// GetFixedSteps returns the number of fixed updates of the current frame.
+func GetFixedSteps() int {
+	return loopInstance.fixedSteps
+}
+
+// GetAlpha returns how far the simulation time has progressed towards the
+// next fixed step, in the range [0, 1). Draw code can use it to interpolate
+// between the previous and the current fixed state.
+func GetAlpha() float32 {
+	return loopInstance.alpha
+}
+
+// GetFrameCount returns the number of frames ticked since Init.
+func GetFrameCount() uint64 {
+	return loopInstance.frameCount
+}
+
+// SetTimeScale sets the factor frame times are multiplied with. 1 is real
+// time, 0.5 runs the simulation at half speed.
+func SetTimeScale(scale float32) {
+	if scale < 0 {
+		logging.Warning("Negative time scale %v clamped to 0.", scale)
+		scale = 0
+	}
+	loopInstance.timeScale = scale
+}
+
+// GetTimeScale returns the factor frame times are multiplied with.
+func GetTimeScale() float32 {
+	return loopInstance.timeScale
+}
+
+// SetPaused freezes or resumes the whole simulation, for debugging together
+// with Step. While frozen, no fixed updates run and the delta time is zero,
+// regardless of the pause modes of the entities.
+//
+// To pause the game, e.g. for a pause menu, set store.AppState.Paused
+// instead. It only stops the entities that are pausable, and keeps time
+// running for the others.
+func SetPaused(paused bool) {
+	loopInstance.paused = paused
+	loopInstance.stepsRequested = 0
+}
+
+// IsPaused returns true if the simulation is paused.
+func IsPaused() bool {
+	return loopInstance.paused
+}
+
+// Step runs exactly one fixed update on the next frame while the loop is
+// frozen by SetPaused. Has no effect if the loop is not frozen.
+func Step() {
+	if loopInstance.paused {
+		loopInstance.stepsRequested++
+	}
+}
+
+func secondsToDuration(seconds float32) time.Duration {
+	return time.Duration(math.Round(float64(seconds) * float64(time.Second)))
+}
+
+func durationToSeconds(d time.Duration) float32 {
+	return float32(d.Seconds())
+}
@@ -0,0 +1,112 @@
+package loop
+
+import (
+	"testing"
+)
+
+// fakeClock reports a fixed frame time that tests can change between ticks.
+type fakeClock struct {
+	frameTime float32
+}
+
+func (c *fakeClock) GetFrameTime() float32 {
+	return c.frameTime
+}
+
+const timestep = 1.0 / 60.0
+
+func almostEqual(a, b, tolerance float32) bool {
+	d := a - b
+	return d < tolerance && d > -tolerance
+}
+
+// TestTickMatchingFrameTime checks that a frame time equal to the fixed
+// timestep runs exactly one fixed step every frame, without drift.
+func TestTickMatchingFrameTime(t *testing.T) {
+	Init(timestep, &fakeClock{frameTime: timestep})
+	for i := 0; i < 10000; i++ {
+		if steps := Tick(); steps != 1 {
+			t.Fatalf("frame %d: expected 1 fixed step, got %d", i, steps)
+		}
+	}
+}
+
+// TestTickAccumulates checks that short frames accumulate into a step and
+// that long frames run several steps, with the remainder exposed as alpha.
+func TestTickAccumulates(t *testing.T) {
+	clock := &fakeClock{frameTime: timestep / 4}
+	Init(timestep, clock)
+
+	for i := 0; i < 3; i++ {
+		if steps := Tick(); steps != 0 {
+			t.Fatalf("expected no fixed step after %d quarter frames, got %d", i+1, steps)
+		}
+	}
+	if !almostEqual(GetAlpha(), 0.75, 0.001) {
+		t.Errorf("expected alpha 0.75, got %f", GetAlpha())
+	}
+	if steps := Tick(); steps != 1 {
+		t.Errorf("expected 1 fixed step after 4 quarter frames, got %d", steps)
+	}
+
+	clock.frameTime = timestep * 2.5
+	if steps := Tick(); steps != 2 {
+		t.Errorf("expected 2 fixed steps for a 2.5 step frame, got %d", steps)
+	}
+	if !almostEqual(GetAlpha(), 0.5, 0.001) {
+		t.Errorf("expected alpha 0.5, got %f", GetAlpha())
+	}
+}
+
+// TestTickClampsSteps checks that a very long frame does not run more than
+// maxFixedSteps fixed steps.
+func TestTickClampsSteps(t *testing.T) {
+	Init(timestep, &fakeClock{frameTime: 10})
+	if steps := Tick(); steps != loopInstance.maxFixedSteps {
+		t.Errorf("expected %d fixed steps, got %d", loopInstance.maxFixedSteps, steps)
+	}
+}
+
+// TestTimeScale checks that the time scale slows down the simulation.
+func TestTimeScale(t *testing.T) {
+	Init(timestep, &fakeClock{frameTime: timestep})
+	SetTimeScale(0.5)
+
+	total := 0
+	for i := 0; i < 10; i++ {
+		total += Tick()
+	}
+	if total != 5 {
+		t.Errorf("expected 5 fixed steps at half speed, got %d", total)
+	}
+	if !almostEqual(GetDeltaTime(), timestep/2, 0.0001) {
+		t.Errorf("expected a scaled delta time of %f, got %f", timestep/2, GetDeltaTime())
+	}
+}
+
+// TestPauseAndStep checks that a paused loop runs no fixed steps, except for
+// the ones explicitly requested by Step.
+func TestPauseAndStep(t *testing.T) {
+	Init(timestep, &fakeClock{frameTime: timestep})
+	SetPaused(true)
+
+	if steps := Tick(); steps != 0 {
+		t.Errorf("expected no fixed steps while paused, got %d", steps)
+	}
+	if GetDeltaTime() != 0 {
+		t.Errorf("expected zero delta time while paused, got %f", GetDeltaTime())
+	}
+
+	Step()
+	if steps := Tick(); steps != 1 {
+		t.Errorf("expected a single step, got %d", steps)
+	}
+	if steps := Tick(); steps != 0 {
+		t.Errorf("expected the single step to be consumed, got %d", steps)
+	}
+
+	SetPaused(false)
+	if steps := Tick(); steps != 1 {
+		t.Errorf("expected 1 fixed step after resuming, got %d", steps)
+	}
+}
@@ -131,3 +131,32 @@ func (m Matrix3) MultiplyV(v rl.Vector2) rl.Vector2 {
 		Y: m.m3*v.X + m.m4*v.Y + m.m5,
 	}
 }
+
+// Determinant returns the determinant of the matrix.
+func (m Matrix3) Determinant() float32 {
+	return m.m0*(m.m4*m.m8-m.m5*m.m7) -
+		m.m1*(m.m3*m.m8-m.m5*m.m6) +
+		m.m2*(m.m3*m.m7-m.m4*m.m6)
+}
+
+// Invert returns the inverse of the matrix. If the matrix is not invertible
+// (e.g. it scales by zero), the second return value is false and the
+// identity matrix is returned.
+func (m Matrix3) Invert() (Matrix3, bool) {
+	det := m.Determinant()
+	if det == 0 {
+		return Matrix3Identity(), false
+	}
+	inv := 1 / det
+	return Matrix3{
+		(m.m4*m.m8 - m.m5*m.m7) * inv,
+		(m.m2*m.m7 - m.m1*m.m8) * inv,
+		(m.m1*m.m5 - m.m2*m.m4) * inv,
+		(m.m5*m.m6 - m.m3*m.m8) * inv,
+		(m.m0*m.m8 - m.m2*m.m6) * inv,
+		(m.m2*m.m3 - m.m0*m.m5) * inv,
+		(m.m3*m.m7 - m.m4*m.m6) * inv,
+		(m.m1*m.m6 - m.m0*m.m7) * inv,
+		(m.m0*m.m4 - m.m1*m.m3) * inv,
+	}, true
+}
@@ -59,3 +59,20 @@ func TestFromTransformations(t *testing.T) {
 		t.Errorf("Generated matrix does not match expected matrix. Got %v, want %v", transformMatrix, expectedMatrix)
 	}
 }
+
+// TestMatrix3Invert tests that a matrix multiplied by its inverse is the identity
+func TestMatrix3Invert(t *testing.T) {
+	m := FromTransformations(rl.Vector2{X: 10, Y: -5}, 30, rl.Vector2{X: 2, Y: 0.5})
+
+	inv, ok := m.Invert()
+	if !ok {
+		t.Fatalf("Expected matrix to be invertible, got %v", m)
+	}
+	if !almostEqualMatrix3(m.Multiply(inv), Matrix3Identity(), 0.001) {
+		t.Errorf("Matrix times its inverse is not the identity, got %v", m.Multiply(inv))
+	}
+
+	if _, ok := Matrix3Scale(rl.Vector2{X: 0, Y: 1}).Invert(); ok {
+		t.Errorf("Expected a matrix scaling by zero not to be invertible")
+	}
+}
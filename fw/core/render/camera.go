@@ -1,6 +1,7 @@
 package render
 
 import (
+	input_event "gorl/fw/core/input/input_event"
 	"gorl/fw/core/math"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
@@ -14,6 +15,8 @@ type renderTarget struct {
 	renderTexture   rl.RenderTexture2D
 }
 
+var _ input_event.Viewport = (*Camera)(nil)
+
 // Camera represents a raylib camera together with a render target, a set
 // of draw flags that determine which drawables should be drawn by this camera
 // and a set of shaders that should be applied to the render target.
@@ -33,11 +36,16 @@ type Camera struct {
 func NewCamera(camTarget, camOffset, displaySize, displayPosition rl.Vector2, drawFlags math.BitFlag) *Camera {
 	rlCamera := rl.NewCamera2D(camOffset, camTarget, 0, 1)
 	camera := &Camera{
-		rlcamera:      &rlCamera,
-		renderTarget:  &renderTarget{displayPosition, displaySize, rl.LoadRenderTexture(int32(displaySize.X), int32(displaySize.Y))},
-		drawFlags:     drawFlags,
-		shaders:       make([]*rl.Shader, 0),
-		bounceTexture: rl.LoadRenderTexture(int32(displaySize.X), int32(displaySize.Y)),
+		rlcamera:     &rlCamera,
+		renderTarget: &renderTarget{DisplayPosition: displayPosition, DisplaySize: displaySize},
+		drawFlags:    drawFlags,
+		shaders:      make([]*rl.Shader, 0),
+	}
+	// headless cameras keep their zero value textures, there is no GPU to
+	// allocate them on.
+	if !rendererInstance.headless {
+		camera.renderTarget.renderTexture = rl.LoadRenderTexture(int32(displaySize.X), int32(displaySize.Y))
+		camera.bounceTexture = rl.LoadRenderTexture(int32(displaySize.X), int32(displaySize.Y))
 	}
 	rendererInstance.cameras = append(rendererInstance.cameras, camera)
 	return camera
@@ -51,17 +59,60 @@ func (c *Camera) Destroy() {
 			break
 		}
 	}
-	rl.UnloadRenderTexture(c.renderTarget.renderTexture)
+	if !rendererInstance.headless {
+		rl.UnloadRenderTexture(c.renderTarget.renderTexture)
+		rl.UnloadRenderTexture(c.bounceTexture)
+	}
 }
 
-// ScreenToWorld converts a screen position to a world position.
+// ScreenToWorld converts a screen position to a world position, taking the
+// position of the camera's viewport on the screen into account.
 func (c *Camera) ScreenToWorld(screenPos rl.Vector2) rl.Vector2 {
-	return rl.GetScreenToWorld2D(screenPos, *c.rlcamera)
+	viewportPos := rl.Vector2Subtract(screenPos, c.renderTarget.DisplayPosition)
+	return rl.GetScreenToWorld2D(viewportPos, *c.rlcamera)
 }
 
 // WorldToScreen converts a world position to a screen position.
 func (c *Camera) WorldToScreen(worldPos rl.Vector2) rl.Vector2 {
-	return rl.GetWorldToScreen2D(worldPos, *c.rlcamera)
+	viewportPos := rl.GetWorldToScreen2D(worldPos, *c.rlcamera)
+	return rl.Vector2Add(viewportPos, c.renderTarget.DisplayPosition)
+}
+
+// ContainsScreenPoint returns true if the screen position is inside the
+// camera's viewport.
+func (c *Camera) ContainsScreenPoint(screenPos rl.Vector2) bool {
+	return rl.CheckCollisionPointRec(screenPos, rl.NewRectangle(
+		c.renderTarget.DisplayPosition.X,
+		c.renderTarget.DisplayPosition.Y,
+		c.renderTarget.DisplaySize.X,
+		c.renderTarget.DisplaySize.Y,
+	))
+}
+
+// GetDisplayPosition returns the position of the camera's viewport on the
+// screen.
+func (c *Camera) GetDisplayPosition() rl.Vector2 {
+	return c.renderTarget.DisplayPosition
+}
+
+// GetDisplaySize returns the size of the camera's viewport on the screen.
+func (c *Camera) GetDisplaySize() rl.Vector2 {
+	return c.renderTarget.DisplaySize
+}
+
+// SetDisplay moves and resizes the camera's viewport on the screen. The
+// render textures of the camera are recreated at the new size.
+func (c *Camera) SetDisplay(displayPosition, displaySize rl.Vector2) {
+	resized := displaySize != c.renderTarget.DisplaySize
+	c.renderTarget.DisplayPosition = displayPosition
+	c.renderTarget.DisplaySize = displaySize
+	if !resized || rendererInstance.headless {
+		return
+	}
+	rl.UnloadRenderTexture(c.renderTarget.renderTexture)
+	rl.UnloadRenderTexture(c.bounceTexture)
+	c.renderTarget.renderTexture = rl.LoadRenderTexture(int32(displaySize.X), int32(displaySize.Y))
+	c.bounceTexture = rl.LoadRenderTexture(int32(displaySize.X), int32(displaySize.Y))
 }
 
 // SetTarget sets the target (position) of the camera.
@@ -23,6 +23,23 @@ type Drawable interface {
 type renderer struct {
 	cameras     []*Camera
 	finalTarget rl.RenderTexture2D
+	screenSize  rl.Vector2
+
+	// windowSize is the size of the window the screen is presented in. The
+	// screen is scaled to fit it, keeping its aspect ratio, see
+	// GetPresentationRect.
+	windowSize rl.Vector2
+	// integerScaling limits the scale of the screen to whole numbers, so
+	// pixels stay sharp.
+	integerScaling bool
+
+	// globalShaders are applied to every camera, after its own shaders.
+	globalShaders []*rl.Shader
+
+	// headless renderers never touch the GPU. They still sort drawables and
+	// resolve which of them each camera would draw, so input routing works
+	// the same as with a window.
+	headless bool
 }
 
 // Init initializes the renderer with the given screen size.
@@ -33,16 +50,62 @@ func Init(screenSize rl.Vector2) {
 			int32(screenSize.X),
 			int32(screenSize.Y),
 		),
+		screenSize: screenSize,
+		windowSize: screenSize,
+	}
+}
+
+// InitHeadless initializes the renderer without allocating any GPU
+// resources. Use this when running without a window, e.g. in tests or CI.
+func InitHeadless(screenSize rl.Vector2) {
+	rendererInstance = renderer{
+		cameras:    []*Camera{},
+		screenSize: screenSize,
+		windowSize: screenSize,
+		headless:   true,
 	}
 }
 
+// IsHeadless returns true if the renderer was initialized with InitHeadless.
+func IsHeadless() bool {
+	return rendererInstance.headless
+}
+
+// GetScreenSize returns the size of the screen the renderer draws to.
+func GetScreenSize() rl.Vector2 {
+	return rendererInstance.screenSize
+}
+
+// GetCameras returns the cameras the renderer draws with, in drawing order.
+func GetCameras() []*Camera {
+	return slices.Clone(rendererInstance.cameras)
+}
+
 // Deinit deinitializes the renderer.
 func Deinit() {
+	if rendererInstance.headless {
+		return
+	}
 	rl.UnloadRenderTexture(rendererInstance.finalTarget)
 }
 
-// SetScreenSize changes the size of the screen.
+// SetScreenSize changes the size of the screen, i.e. the resolution the game
+// is rendered at. The viewports of the cameras are scaled along, so a camera
+// covering the whole screen still does afterwards.
 func SetScreenSize(screenSize rl.Vector2) {
+	old := rendererInstance.screenSize
+	rendererInstance.screenSize = screenSize
+	scale := rl.NewVector2(screenSize.X/old.X, screenSize.Y/old.Y)
+	for _, camera := range rendererInstance.cameras {
+		camera.SetDisplay(
+			rl.Vector2Multiply(camera.renderTarget.DisplayPosition, scale),
+			rl.Vector2Multiply(camera.renderTarget.DisplaySize, scale),
+		)
+	}
+	updateMouseMapping()
+	if rendererInstance.headless {
+		return
+	}
 	rl.UnloadRenderTexture(rendererInstance.finalTarget)
 	rendererInstance.finalTarget = rl.LoadRenderTexture(
 		int32(screenSize.X),
@@ -50,20 +113,76 @@ func SetScreenSize(screenSize rl.Vector2) {
 	)
 }
 
+// SetWindowSize tells the renderer the size of the window the screen is
+// presented in. The backend calls this when the window changes.
+func SetWindowSize(windowSize rl.Vector2) {
+	rendererInstance.windowSize = windowSize
+	updateMouseMapping()
+}
+
+// SetIntegerScaling limits the scale of the screen in the window to whole
+// numbers if enabled, leaving wider borders. Screens larger than the window
+// are still scaled down.
+func SetIntegerScaling(enabled bool) {
+	rendererInstance.integerScaling = enabled
+	updateMouseMapping()
+}
+
+// GetPresentationRect returns the area of the window the screen is drawn to.
+// The screen is scaled as large as it fits, keeping its aspect ratio, and
+// centered. The rest of the window is filled with black bars.
+func GetPresentationRect() rl.Rectangle {
+	screen, window := rendererInstance.screenSize, rendererInstance.windowSize
+	scale := min(window.X/screen.X, window.Y/screen.Y)
+	if rendererInstance.integerScaling && scale >= 1 {
+		scale = float32(int(scale))
+	}
+	size := rl.Vector2Scale(screen, scale)
+	return rl.NewRectangle(
+		float32(int((window.X-size.X)/2)),
+		float32(int((window.Y-size.Y)/2)),
+		size.X, size.Y,
+	)
+}
+
+// updateMouseMapping makes raylib report the mouse position on the screen
+// instead of in the window, so input events match what the cameras draw.
+func updateMouseMapping() {
+	if rendererInstance.headless {
+		return
+	}
+	rect := GetPresentationRect()
+	rl.SetMouseOffset(-int(rect.X), -int(rect.Y))
+	rl.SetMouseScale(rendererInstance.screenSize.X/rect.Width, rendererInstance.screenSize.Y/rect.Height)
+}
+
 // rendererInstance is the global renderer instance.
 var rendererInstance renderer
 
 // Draw draws the given drawable to the screen, using all cameras.
-// Returns the sorted list of drawables, back to front.
-func Draw(drawables []Drawable) []input.InputReceiver {
+// Returns the input receivers of the drawn drawables together with the
+// camera that drew them, back to front. Drawables drawn by several cameras
+// appear once per camera.
+func Draw(drawables []Drawable) []input.DrawnReceiver {
 
-	inputReceivers := []input.InputReceiver{}
+	inputReceivers := []input.DrawnReceiver{}
 
 	// sort the drawables by draw index
 	slices.SortStableFunc(drawables, func(l, r Drawable) int {
 		return int(l.GetDrawIndex() - r.GetDrawIndex())
 	})
 
+	if rendererInstance.headless {
+		for _, camera := range rendererInstance.cameras {
+			for _, drawable := range drawables {
+				if drawable.ShouldDraw(camera.drawFlags) {
+					inputReceivers = append(inputReceivers, input.DrawnReceiver{Receiver: drawable.AsInputReceiver(), Camera: camera})
+				}
+			}
+		}
+		return inputReceivers
+	}
+
 	for _, camera := range rendererInstance.cameras {
 		rl.BeginTextureMode(camera.renderTarget.renderTexture)
 		rl.BeginMode2D(*camera.rlcamera)
@@ -72,7 +191,7 @@ func Draw(drawables []Drawable) []input.InputReceiver {
 		// Draw all drawables that should be drawn by this camera.
 		for _, drawable := range drawables {
 			if drawable.ShouldDraw(camera.drawFlags) {
-				inputReceivers = append(inputReceivers, drawable.AsInputReceiver())
+				inputReceivers = append(inputReceivers, input.DrawnReceiver{Receiver: drawable.AsInputReceiver(), Camera: camera})
 				drawable.Draw()
 			}
 		}
@@ -81,15 +200,20 @@ func Draw(drawables []Drawable) []input.InputReceiver {
 		rl.EndTextureMode()
 	}
 
+	// Apply per camera shaders. This has to happen before drawing to the
+	// final target, since each shader pass switches the texture mode.
+	results := make([]*rl.RenderTexture2D, len(rendererInstance.cameras))
+	for i, camera := range rendererInstance.cameras {
+		results[i] = applyShaders(camera)
+	}
+
 	// Draw all camera render targets to the final target.
-	// Apply per camera shaders in the process.
 	rl.BeginTextureMode(rendererInstance.finalTarget)
 	rl.ClearBackground(colorscheme.Colorscheme.Color16.ToRGBA())
-	for _, camera := range rendererInstance.cameras {
-		applyShaders(camera)
+	for i, camera := range rendererInstance.cameras {
 		rl.DrawTexturePro(
-			camera.renderTarget.renderTexture.Texture,
-			rl.NewRectangle(0, 0, float32(camera.renderTarget.renderTexture.Texture.Width), -float32(camera.renderTarget.renderTexture.Texture.Height)),
+			results[i].Texture,
+			rl.NewRectangle(0, 0, float32(results[i].Texture.Width), -float32(results[i].Texture.Height)),
 			rl.NewRectangle(
 				camera.renderTarget.DisplayPosition.X,
 				camera.renderTarget.DisplayPosition.Y,
@@ -102,13 +226,14 @@ func Draw(drawables []Drawable) []input.InputReceiver {
 	}
 	rl.EndTextureMode()
 
-	// Draw the final target to the screen.
+	// Draw the final target to the window, letterboxed.
 	// TODO: here we could apply final post processing shaders.
 
+	rl.ClearBackground(rl.Black)
 	rl.DrawTexturePro(
 		rendererInstance.finalTarget.Texture,
 		rl.NewRectangle(0, 0, float32(rendererInstance.finalTarget.Texture.Width), -float32(rendererInstance.finalTarget.Texture.Height)),
-		rl.NewRectangle(0, 0, float32(rendererInstance.finalTarget.Texture.Width), float32(rendererInstance.finalTarget.Texture.Height)),
+		GetPresentationRect(),
 		rl.NewVector2(0, 0),
 		0, rl.White,
 	)
@@ -116,13 +241,17 @@ func Draw(drawables []Drawable) []input.InputReceiver {
 	return inputReceivers
 }
 
-// ApplyShaders applies the shaders of the camera to the cameras render target.
-func applyShaders(camera *Camera) {
+// applyShaders applies the shaders of the camera, followed by the global
+// shaders, to the cameras render target. Returns the texture holding the
+// result.
+func applyShaders(camera *Camera) *rl.RenderTexture2D {
 	currentSource := &camera.renderTarget.renderTexture
 	currentTarget := &camera.bounceTexture
 
-	for _, shader := range camera.shaders {
+	shaders := append(slices.Clip(camera.shaders), rendererInstance.globalShaders...)
+	for _, shader := range shaders {
 		rl.BeginTextureMode(*currentTarget)
+		rl.ClearBackground(rl.Blank)
 		rl.BeginShaderMode(*shader)
 		rl.DrawTexturePro(
 			currentSource.Texture,
@@ -133,19 +262,24 @@ func applyShaders(camera *Camera) {
 		)
 		rl.EndShaderMode()
 		rl.EndTextureMode()
-		tmp := currentSource
-		currentSource = currentTarget
-		currentTarget = tmp
+		currentSource, currentTarget = currentTarget, currentSource
 	}
 
-	// if the last draw was to the bounce texture, draw it to the cameras target.
-	if currentSource == &camera.bounceTexture {
-		rl.DrawTexturePro(
-			currentSource.Texture,
-			rl.NewRectangle(0, 0, float32(currentSource.Texture.Width), -float32(currentSource.Texture.Height)),
-			rl.NewRectangle(0, 0, float32(currentSource.Texture.Width), float32(currentSource.Texture.Height)),
-			rl.NewVector2(0, 0),
-			0, rl.White,
-		)
+	return currentSource
+}
+
+// AddGlobalShader adds a shader that is applied to every camera, after the
+// camera's own shaders. Used e.g. for scene transitions.
+func AddGlobalShader(shader *rl.Shader) {
+	rendererInstance.globalShaders = append(rendererInstance.globalShaders, shader)
+}
+
+// RemoveGlobalShader removes a shader added with AddGlobalShader.
+func RemoveGlobalShader(shader *rl.Shader) {
+	for i, s := range rendererInstance.globalShaders {
+		if s == shader {
+			rendererInstance.globalShaders = append(rendererInstance.globalShaders[:i], rendererInstance.globalShaders[i+1:]...)
+			break
+		}
 	}
 }
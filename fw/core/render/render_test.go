@@ -0,0 +1,39 @@
+package render
+
+import (
+	"testing"
+
+	"gorl/fw/core/math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestPresentationRect(t *testing.T) {
+	InitHeadless(rl.NewVector2(320, 180))
+
+	// a 16:9 screen in a 4:3 window gets bars at the top and bottom.
+	SetWindowSize(rl.NewVector2(800, 600))
+	if rect := GetPresentationRect(); rect != rl.NewRectangle(0, 75, 800, 450) {
+		t.Errorf("want the screen letterboxed, got %v", rect)
+	}
+
+	// with integer scaling, 2.5 times is rounded down to 2 times.
+	SetIntegerScaling(true)
+	if rect := GetPresentationRect(); rect != rl.NewRectangle(80, 120, 640, 360) {
+		t.Errorf("want the screen scaled by 2, got %v", rect)
+	}
+}
+
+func TestSetScreenSizeScalesCameras(t *testing.T) {
+	InitHeadless(rl.NewVector2(320, 180))
+	full := NewCamera(rl.Vector2Zero(), rl.Vector2Zero(), rl.NewVector2(320, 180), rl.Vector2Zero(), math.Flag0)
+	minimap := NewCamera(rl.Vector2Zero(), rl.Vector2Zero(), rl.NewVector2(80, 45), rl.NewVector2(240, 0), math.Flag0)
+
+	SetScreenSize(rl.NewVector2(640, 360))
+	if full.GetDisplaySize() != rl.NewVector2(640, 360) {
+		t.Errorf("full screen camera should cover the new screen, got %v", full.GetDisplaySize())
+	}
+	if minimap.GetDisplayPosition() != rl.NewVector2(480, 0) || minimap.GetDisplaySize() != rl.NewVector2(160, 90) {
+		t.Errorf("minimap should keep its place, got %v %v", minimap.GetDisplayPosition(), minimap.GetDisplaySize())
+	}
+}
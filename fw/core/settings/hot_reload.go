@@ -0,0 +1,68 @@
+package settings
+
+import (
+	"os"
+	"time"
+)
+
+// reloadInterval is how often PollFiles looks at the settings files.
+const reloadInterval = 500 * time.Millisecond
+
+var hotReload = struct {
+	enabled   bool
+	lastCheck time.Time
+	// modTimes are the modification times of the settings files when they
+	// were last read. Missing files have the zero time.
+	modTimes map[string]time.Time
+}{modTimes: map[string]time.Time{}}
+
+// EnableHotReload makes PollFiles reload the settings once a settings file
+// changes on disk. Meant for development, to tweak settings while the game
+// runs.
+func EnableHotReload(enabled bool) {
+	hotReload.enabled = enabled
+}
+
+// PollFiles reloads the settings with the sources of the last Load, if hot
+// reload is enabled and a settings file changed since it was read. It looks
+// at the files at most every half second, so it can be called every frame.
+//
+// The user file is read again as well, so changes made by Modify and not
+// saved yet are lost. If the files are invalid, e.g. while they are edited,
+// the current settings are kept and the error is returned, once per change.
+func PollFiles() error {
+	if !hotReload.enabled || time.Since(hotReload.lastCheck) < reloadInterval {
+		return nil
+	}
+	hotReload.lastCheck = time.Now()
+
+	changed := false
+	for path, modTime := range currentModTimes() {
+		if hotReload.modTimes[path] != modTime {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return Load(sources)
+}
+
+// rememberModTimes records the modification times of the settings files.
+func rememberModTimes() {
+	hotReload.modTimes = currentModTimes()
+}
+
+func currentModTimes() map[string]time.Time {
+	modTimes := map[string]time.Time{}
+	for _, path := range []string{sources.File, sources.UserFile} {
+		if path == "" {
+			continue
+		}
+		modTimes[path] = time.Time{}
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+	return modTimes
+}
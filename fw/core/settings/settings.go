@@ -1,10 +1,16 @@
 package settings
 
 import (
-	"encoding/json"
-	"os"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"gorl/fw/core/store"
 )
 
+// GameSettings holds the settings of the game. The json keys are used in the
+// settings files, and to derive the names of the environment variables and
+// command line flags, see Load.
 type GameSettings struct {
 	// Meta
 	Version string `json:"version"` // 0.0.0
@@ -16,6 +22,8 @@ type GameSettings struct {
 	RenderHeight    int  `json:"renderHeight"`    // 1080
 	TargetFps       int  `json:"targetFps"`       // 144
 	Fullscreen      bool `json:"fullscreen"`      // false
+	VSync           bool `json:"vsync"`           // false
+	IntegerScaling  bool `json:"integerScaling"`  // false
 	EnableCrtEffect bool `json:"enableCrtEffect"` // true
 	// Gameplay
 	MouseSensitivity float32 `json:"mouseSensitivity"` // 1.0
@@ -28,16 +36,27 @@ type GameSettings struct {
 }
 
 var (
-	settings *GameSettings
+	// settings is replaced as a whole on every change, so a pointer returned
+	// by CurrentSettings never changes under its holder.
+	settings atomic.Pointer[GameSettings]
+
+	// changes passes each change to the watchers, see Watch.
+	changes = store.New()
 )
 
+// change is the value passed to the watchers.
+type change struct {
+	old, new *GameSettings
+}
+
 // Get the current settings
 func CurrentSettings() *GameSettings {
-	return settings
+	return settings.Load()
 }
 
-func UseFallbackSettings() {
-	settings = &GameSettings{
+// Defaults returns the default settings, the lowest layer of Load.
+func Defaults() *GameSettings {
+	return &GameSettings{
 		Version:          "0.0.0",
 		Title:            "made with gorl",
 		ScreenWidth:      1920,
@@ -46,6 +65,8 @@ func UseFallbackSettings() {
 		RenderHeight:     1080,
 		TargetFps:        144,
 		Fullscreen:       false,
+		VSync:            false,
+		IntegerScaling:   false,
 		EnableCrtEffect:  true,
 		MouseSensitivity: 1.0,
 		SoundVolume:      0.5,
@@ -54,19 +75,48 @@ func UseFallbackSettings() {
 	}
 }
 
-func LoadSettings(path string) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// UseFallbackSettings replaces the current settings by the defaults, e.g.
+// after Load failed. The sources of that Load are still used by PollFiles
+// and SaveSettings.
+func UseFallbackSettings() {
+	use(Defaults())
+}
 
-	settings = new(GameSettings)
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(settings)
-	if err != nil {
-		return err
+// Validate returns an error describing every invalid setting, or nil.
+func (s *GameSettings) Validate() error {
+	var errs []error
+	atLeast := func(key string, value, min int) {
+		if value < min {
+			errs = append(errs, fmt.Errorf("%v must be at least %v, got %v", key, min, value))
+		}
 	}
+	atLeast("screenWidth", s.ScreenWidth, 1)
+	atLeast("screenHeight", s.ScreenHeight, 1)
+	atLeast("renderWidth", s.RenderWidth, 1)
+	atLeast("renderHeight", s.RenderHeight, 1)
+	atLeast("targetFps", s.TargetFps, 1)
+	if s.MouseSensitivity <= 0 {
+		errs = append(errs, fmt.Errorf("mouseSensitivity must be greater than 0, got %v", s.MouseSensitivity))
+	}
+	if s.SoundVolume < 0 || s.SoundVolume > 1 {
+		errs = append(errs, fmt.Errorf("soundVolume must be between 0 and 1, got %v", s.SoundVolume))
+	}
+	if s.LogPath == "" {
+		errs = append(errs, errors.New("logPath must not be empty"))
+	}
+	return errors.Join(errs...)
+}
 
-	return nil
+// Watch calls fn with the old and the new settings every time the settings
+// change after the first Load, e.g. by Modify or a hot reload.
+func Watch(fn func(old, new *GameSettings)) *store.Watcher {
+	return store.WatchIn(changes, "", func(c change) { fn(c.old, c.new) })
+}
+
+// use makes s the current settings, and tells the watchers if they changed.
+func use(s *GameSettings) {
+	old := settings.Swap(s)
+	if old != nil && *old != *s {
+		store.SetIn(changes, "", change{old, s})
+	}
 }
@@ -0,0 +1,157 @@
+package settings
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSettings(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+	file, userFile := filepath.Join(dir, "settings.json"), filepath.Join(dir, "user.json")
+	// keys match regardless of case, like the old PascalCase files.
+	writeSettings(t, file, `{"ScreenWidth": 1280, "targetFps": 60, "title": "file"}`)
+	writeSettings(t, userFile, `{"targetFps": 30, "soundVolume": 0.2}`)
+	t.Setenv("TEST_TARGET_FPS", "90")
+	t.Setenv("TEST_FULLSCREEN", "true")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlags(fs)
+	if err := fs.Parse([]string{"--targetFps", "120"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Load(Sources{File: file, UserFile: userFile, EnvPrefix: "TEST_", Flags: fs}); err != nil {
+		t.Fatal(err)
+	}
+	s := CurrentSettings()
+	if s.ScreenWidth != 1280 || s.Title != "file" || s.SoundVolume != 0.2 || !s.Fullscreen || s.TargetFps != 120 {
+		t.Fatalf("layers applied in the wrong order: %+v", *s)
+	}
+	if s.ScreenHeight != 1080 {
+		t.Errorf("missing keys should keep the default, got %v", s.ScreenHeight)
+	}
+}
+
+func TestInvalidSettingsAreRejected(t *testing.T) {
+	UseFallbackSettings()
+	path := filepath.Join(t.TempDir(), "settings.json")
+
+	for content, want := range map[string]string{
+		`{"screenWidht": 1280}`:                `unknown setting "screenWidht"`,
+		`{"targetFps": "fast"}`:                "targetFps",
+		`{"targetFps": 0, "soundVolume": 1.5}`: "targetFps must be at least 1, got 0\nsoundVolume must be between 0 and 1, got 1.5",
+	} {
+		writeSettings(t, path, content)
+		err := LoadSettings(path)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("loading %v: want error containing %q, got %v", content, want, err)
+		}
+	}
+	if *CurrentSettings() != *Defaults() {
+		t.Errorf("invalid settings should not be applied")
+	}
+}
+
+func TestModifyAndSave(t *testing.T) {
+	dir := t.TempDir()
+	file, userFile := filepath.Join(dir, "settings.json"), filepath.Join(dir, "user.json")
+	writeSettings(t, file, `{"title": "file"}`)
+	if err := Load(Sources{File: file, UserFile: userFile}); err != nil {
+		t.Fatal(err)
+	}
+
+	var changed []int
+	w := Watch(func(old, new *GameSettings) { changed = append(changed, old.TargetFps, new.TargetFps) })
+	defer w.Stop()
+	if err := Modify(func(s *GameSettings) { s.TargetFps = 0 }); err == nil {
+		t.Errorf("invalid change should be rejected")
+	}
+	if err := Modify(func(s *GameSettings) { s.TargetFps = 30 }); err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 2 || changed[0] != 144 || changed[1] != 30 {
+		t.Fatalf("watcher should see the change from 144 to 30, got %v", changed)
+	}
+
+	if err := SaveSettings(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(userFile)
+	if strings.TrimSpace(string(data)) != "{\n    \"targetFps\": 30\n}" {
+		t.Errorf("only the changed setting should be saved, got %s", data)
+	}
+}
+
+func TestHotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	writeSettings(t, path, `{"targetFps": 60}`)
+	if err := LoadSettings(path); err != nil {
+		t.Fatal(err)
+	}
+	EnableHotReload(true)
+	defer EnableHotReload(false)
+
+	writeSettings(t, path, `{"targetFps": 30}`)
+	later := time.Now().Add(time.Second)
+	os.Chtimes(path, later, later)
+	hotReload.lastCheck = time.Time{}
+	if err := PollFiles(); err != nil || CurrentSettings().TargetFps != 30 {
+		t.Fatalf("changed file should be reloaded, got %v, %v", CurrentSettings().TargetFps, err)
+	}
+
+	// a broken file is reported once, and the settings are kept.
+	writeSettings(t, path, `{"targetFps": `)
+	later = later.Add(time.Second)
+	os.Chtimes(path, later, later)
+	hotReload.lastCheck = time.Time{}
+	if err := PollFiles(); err == nil || CurrentSettings().TargetFps != 30 {
+		t.Fatalf("broken file should be reported and ignored, got %v", err)
+	}
+	hotReload.lastCheck = time.Time{}
+	if err := PollFiles(); err != nil {
+		t.Errorf("unchanged broken file should not be reported again, got %v", err)
+	}
+}
+
+func TestRecoverFromFallback(t *testing.T) {
+	dir := t.TempDir()
+	file, userFile := filepath.Join(dir, "settings.json"), filepath.Join(dir, "user.json")
+	writeSettings(t, file, `{"targetFps": `)
+	writeSettings(t, userFile, `{"soundVolume": 0.2}`)
+	if err := Load(Sources{File: file, UserFile: userFile}); err == nil {
+		t.Fatal("broken file should be reported")
+	}
+	UseFallbackSettings()
+	EnableHotReload(true)
+	defer EnableHotReload(false)
+
+	// the player's file is still saved, with its values.
+	if err := Modify(func(s *GameSettings) { s.Fullscreen = true }); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(userFile); !strings.Contains(string(data), "soundVolume") {
+		t.Errorf("saving should keep the player's values, got %s", data)
+	}
+
+	// and fixing the broken file reloads it.
+	writeSettings(t, file, `{"targetFps": 30}`)
+	later := time.Now().Add(time.Second)
+	os.Chtimes(file, later, later)
+	hotReload.lastCheck = time.Time{}
+	if err := PollFiles(); err != nil || CurrentSettings().TargetFps != 30 {
+		t.Fatalf("fixed file should be reloaded, got %v, %v", CurrentSettings().TargetFps, err)
+	}
+}
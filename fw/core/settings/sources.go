@@ -0,0 +1,314 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Sources are the layers the settings are built from. Each layer overrides
+// the values set by the ones before it:
+//
+//  1. the defaults, see Defaults
+//  2. File, the settings shipped with the game
+//  3. UserFile, the settings changed by the player, written by SaveSettings
+//  4. environment variables named EnvPrefix + the key in upper snake case,
+//     e.g. GORL_SCREEN_WIDTH
+//  5. the command line flags registered by RegisterFlags, e.g. --screenWidth
+//
+// Files only have to contain the keys they change.
+type Sources struct {
+	File      string
+	UserFile  string
+	EnvPrefix string
+	Flags     *flag.FlagSet
+}
+
+var (
+	// sources are the sources of the last Load, even if it failed.
+	sources Sources
+	// userValues are the values of the user layer, by key. They are written
+	// by SaveSettings.
+	userValues = map[string]json.RawMessage{}
+)
+
+// field is a setting of GameSettings.
+type field struct {
+	key   string
+	index int
+}
+
+// fields are the settings, in the order they are declared.
+var fields = func() []field {
+	t := reflect.TypeOf(GameSettings{})
+	fields := make([]field, t.NumField())
+	for i := range fields {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[i] = field{key: key, index: i}
+	}
+	return fields
+}()
+
+// fieldByKey returns the setting of a key. Like encoding/json, keys match
+// regardless of their case.
+func fieldByKey(key string) (field, bool) {
+	for _, f := range fields {
+		if strings.EqualFold(f.key, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// LoadSettings loads the settings from a single file over the defaults.
+func LoadSettings(path string) error {
+	return Load(Sources{File: path})
+}
+
+// Load builds the settings from the sources, validates them and makes them
+// the current settings. Only the user file may be missing. On error, the
+// current settings are kept and the error names the source and the setting
+// at fault.
+//
+// The sources are remembered even if they are invalid, so after falling back
+// with UseFallbackSettings, PollFiles still reloads the files once they are
+// fixed, and SaveSettings still writes the user file, keeping its values if
+// it could be read.
+func Load(src Sources) error {
+	if src.UserFile != sources.UserFile {
+		userValues = map[string]json.RawMessage{}
+	}
+	sources = src
+	rememberModTimes()
+
+	s, user, err := build(src)
+	if err != nil {
+		if src.UserFile != "" {
+			if values, readErr := readFile(src.UserFile); readErr == nil {
+				userValues = values
+			}
+		}
+		return err
+	}
+	userValues = user
+	use(s)
+	return nil
+}
+
+// build applies the layers of the sources over the defaults.
+func build(src Sources) (*GameSettings, map[string]json.RawMessage, error) {
+	s := Defaults()
+	user := map[string]json.RawMessage{}
+	if src.File != "" {
+		values, err := readFile(src.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := applyValues(s, values); err != nil {
+			return nil, nil, fmt.Errorf("settings file %v: %w", src.File, err)
+		}
+	}
+	if src.UserFile != "" {
+		values, err := readFile(src.UserFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, nil, err
+		}
+		if err == nil {
+			if err := applyValues(s, values); err != nil {
+				return nil, nil, fmt.Errorf("settings file %v: %w", src.UserFile, err)
+			}
+			user = values
+		}
+	}
+	if src.EnvPrefix != "" {
+		for _, f := range fields {
+			name := src.EnvPrefix + envName(f.key)
+			if value, ok := os.LookupEnv(name); ok {
+				if err := setString(s, f, value); err != nil {
+					return nil, nil, fmt.Errorf("environment variable %v: %w", name, err)
+				}
+			}
+		}
+	}
+	if src.Flags != nil {
+		var err error
+		src.Flags.Visit(func(fl *flag.Flag) {
+			value, ok := fl.Value.(*flagValue)
+			if ok && err == nil {
+				if setErr := setString(s, value.field, value.value); setErr != nil {
+					err = fmt.Errorf("flag --%v: %w", fl.Name, setErr)
+				}
+			}
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := s.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid settings: %w", err)
+	}
+	return s, user, nil
+}
+
+// readFile reads a settings file into its values by key. Unknown keys are an
+// error, since they are usually typos.
+func readFile(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("settings file %v: %w", path, err)
+	}
+	values := make(map[string]json.RawMessage, len(raw))
+	for key, value := range raw {
+		f, ok := fieldByKey(key)
+		if !ok {
+			return nil, fmt.Errorf("settings file %v: unknown setting %q", path, key)
+		}
+		values[f.key] = value
+	}
+	return values, nil
+}
+
+// applyValues sets the settings of the values by key.
+func applyValues(s *GameSettings, values map[string]json.RawMessage) error {
+	for _, f := range fields {
+		value, ok := values[f.key]
+		if !ok {
+			continue
+		}
+		ptr := reflect.ValueOf(s).Elem().Field(f.index).Addr().Interface()
+		if err := json.Unmarshal(value, ptr); err != nil {
+			return fmt.Errorf("%v: %w", f.key, err)
+		}
+	}
+	return nil
+}
+
+// setString sets a setting from its text, as given by an environment
+// variable or a flag.
+func setString(s *GameSettings, f field, text string) error {
+	v := reflect.ValueOf(s).Elem().Field(f.index)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Int:
+		i, err := strconv.Atoi(text)
+		if err != nil {
+			return fmt.Errorf("%v must be an integer, got %q", f.key, text)
+		}
+		v.SetInt(int64(i))
+	case reflect.Float32:
+		x, err := strconv.ParseFloat(text, 32)
+		if err != nil {
+			return fmt.Errorf("%v must be a number, got %q", f.key, text)
+		}
+		v.SetFloat(x)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("%v must be true or false, got %q", f.key, text)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("%v can't be set from text", f.key)
+	}
+	return nil
+}
+
+// envName turns a key into upper snake case, e.g. screenWidth to
+// SCREEN_WIDTH.
+func envName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// flagValue is the command line flag of a setting.
+type flagValue struct {
+	field field
+	value string
+}
+
+func (v *flagValue) String() string { return v.value }
+func (v *flagValue) Set(value string) error {
+	// check the value right away, so the flag package reports it.
+	if err := setString(Defaults(), v.field, value); err != nil {
+		return err
+	}
+	v.value = value
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given without a value, e.g.
+// --fullscreen.
+func (v *flagValue) IsBoolFlag() bool {
+	return reflect.TypeOf(GameSettings{}).Field(v.field.index).Type.Kind() == reflect.Bool
+}
+
+// RegisterFlags adds a command line flag for every setting to the flag set,
+// named after its key. Pass the flag set to Load as Sources.Flags, after it
+// was parsed.
+func RegisterFlags(fs *flag.FlagSet) {
+	for _, f := range fields {
+		fs.Var(&flagValue{field: f}, f.key, "overrides the "+f.key+" setting")
+	}
+}
+
+// Modify changes the current settings at runtime, e.g. from an options menu.
+// The changed values are part of the user layer, so they are written by the
+// next SaveSettings. Invalid changes are not applied.
+func Modify(fn func(s *GameSettings)) error {
+	old := CurrentSettings()
+	s := *old
+	fn(&s)
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+	oldValue, newValue := reflect.ValueOf(old).Elem(), reflect.ValueOf(&s).Elem()
+	for _, f := range fields {
+		value := newValue.Field(f.index).Interface()
+		if value == oldValue.Field(f.index).Interface() {
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		userValues[f.key] = data
+	}
+	use(&s)
+	return nil
+}
+
+// SaveSettings writes the user layer to the user file of the sources, i.e.
+// the values read from it and the ones changed by Modify. Values of the other
+// layers, e.g. from flags, are not written.
+func SaveSettings() error {
+	if sources.UserFile == "" {
+		return errors.New("no user settings file to save to")
+	}
+	data, err := json.MarshalIndent(userValues, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(sources.UserFile, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	// the game wrote the file itself, so it is not reloaded.
+	rememberModTimes()
+	return nil
+}
@@ -4,6 +4,15 @@ package store
 
 type AppState struct {
 	ShouldQuit bool
+
+	// Paused is the global pause state, the one games should use, e.g. for
+	// a pause menu. Entities stop or start being processed depending on their
+	// pause mode, see entities.PauseMode, and physics is halted. Time keeps
+	// running, so entities processed during pause still get a delta time.
+	//
+	// loop.SetPaused is a different switch, freezing the whole simulation
+	// for debugging.
+	Paused bool
 }
 
 func NewAppState() *AppState {
@@ -1,22 +1,48 @@
 package store
 
 import (
-	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
 )
 
-// store holds values keyed by their type.
-type store struct {
-	data map[reflect.Type]interface{}
+// Store holds values keyed by their type and a name, so several values of the
+// same type can be stored side by side. Values stored with Add and read with
+// Get use the empty name.
+//
+// The package functions use the global store. Separate stores, e.g. the
+// store of a scene, are used with the functions ending in In, such as SetIn.
+// A store is safe for concurrent use, e.g. by background loaders.
+//
+// The lock and the watchers only cover the stored values themselves. When a
+// pointer is stored, changes made through it are neither locked nor seen by
+// Watch. Store values instead, and change them with Set or Update, if they
+// are shared with other goroutines or watched.
+type Store struct {
+	mu       sync.RWMutex
+	data     map[any]any
+	watchers map[any][]*Watcher
+}
+
+// slotKey identifies the slot of a type and name, without reflection.
+type slotKey[T any] struct {
+	name string
 }
 
 // globalStore is the default store instance.
 var globalStore = newStore()
 
-// newStore creates a new instance of Store.
-func newStore() *store {
-	s := &store{
-		data: make(map[reflect.Type]interface{}),
+// New returns a new, empty store.
+func New() *Store {
+	return &Store{
+		data:     make(map[any]any),
+		watchers: make(map[any][]*Watcher),
 	}
+}
+
+// newStore creates the global store, with the premade stored types.
+func newStore() *Store {
+	s := New()
 
 	// Add premade stored types.
 	// These should always be present in the store.
@@ -25,24 +51,174 @@ func newStore() *store {
 	return s
 }
 
-// Add adds or replaces a value in the store keyed by its type.
+// Watcher is the handle of a function watching a slot of a store.
+type Watcher struct {
+	active atomic.Bool
+	fn     any
+	stop   func()
+}
+
+// Stop removes the watcher, it is not called for later changes. Calling it
+// more than once has no effect.
+func (w *Watcher) Stop() {
+	if w != nil && w.active.Swap(false) {
+		w.stop()
+	}
+}
+
+// Add adds or replaces a value in the global store keyed by its type.
+//
+// Get returns a copy of the value. Values can be added as pointers to be
+// changed in place, but then the changes are not locked or watched, see
+// Store.
 func Add[T any](value T) {
-	t := reflect.TypeOf(value)
-	globalStore.data[t] = value
+	SetIn(globalStore, "", value)
 }
 
-// Get retrieves a value from the store by its type. It returns the value and a
-// boolean indicating if it was found.
+// Get retrieves a value from the global store by its type. It returns the
+// value and a boolean indicating if it was found.
 func Get[T any]() (T, bool) {
-	t := reflect.TypeOf((*T)(nil)).Elem()
-	v, ok := globalStore.data[t]
+	return GetIn[T](globalStore, "")
+}
+
+// Set adds or replaces the value of type T with the given name in the global
+// store, and calls the watchers of the slot.
+func Set[T any](name string, value T) {
+	SetIn(globalStore, name, value)
+}
+
+// GetNamed retrieves the value of type T with the given name from the global
+// store. It returns the value and a boolean indicating if it was found.
+func GetNamed[T any](name string) (T, bool) {
+	return GetIn[T](globalStore, name)
+}
+
+// Update replaces the value of type T with the given name in the global store
+// by the result of fn, see UpdateIn.
+func Update[T any](name string, fn func(value T, found bool) T) T {
+	return UpdateIn(globalStore, name, fn)
+}
+
+// Delete removes the value of type T with the given name from the global
+// store.
+func Delete[T any](name string) {
+	DeleteIn[T](globalStore, name)
+}
+
+// Watch calls fn with the new value every time the value of type T with the
+// given name in the global store is set.
+func Watch[T any](name string, fn func(value T)) *Watcher {
+	return WatchIn(globalStore, name, fn)
+}
+
+// SetIn adds or replaces the value of type T with the given name in the
+// store, and calls the watchers of the slot.
+func SetIn[T any](s *Store, name string, value T) {
+	key := slotKey[T]{name}
+	s.mu.Lock()
+	s.data[key] = value
+	watchers := s.watchers[key]
+	s.mu.Unlock()
+	notify(watchers, value)
+}
+
+// GetIn retrieves the value of type T with the given name from the store. It
+// returns the value and a boolean indicating if it was found.
+func GetIn[T any](s *Store, name string) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.data[slotKey[T]{name}]
 	if !ok {
 		return *new(T), false
 	}
 	return v.(T), true
 }
 
-func addPremade[T any](value T, s *store) {
-	t := reflect.TypeOf(value)
-	s.data[t] = value
+// UpdateIn replaces the value of type T with the given name in the store by
+// the result of fn, and returns the new value. fn gets the current value, or
+// the zero value if there is none yet.
+//
+// The store is locked while fn runs, so concurrent updates are not lost. fn
+// must not use the store itself. The watchers are called after it returns.
+func UpdateIn[T any](s *Store, name string, fn func(value T, found bool) T) T {
+	key := slotKey[T]{name}
+	s.mu.Lock()
+	old, ok := s.data[key]
+	var value T
+	if ok {
+		value = fn(old.(T), true)
+	} else {
+		value = fn(value, false)
+	}
+	s.data[key] = value
+	watchers := s.watchers[key]
+	s.mu.Unlock()
+	notify(watchers, value)
+	return value
+}
+
+// DeleteIn removes the value of type T with the given name from the store.
+// Its watchers stay registered.
+func DeleteIn[T any](s *Store, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, slotKey[T]{name})
+}
+
+// WatchIn calls fn with the new value every time the value of type T with the
+// given name in the store is set, until the watcher is stopped. Watchers are
+// called in the order they were added, after the store was unlocked, so they
+// may use the store.
+func WatchIn[T any](s *Store, name string, fn func(value T)) *Watcher {
+	if fn == nil {
+		panic("store: watcher is nil")
+	}
+	key := slotKey[T]{name}
+	w := &Watcher{fn: fn}
+	w.active.Store(true)
+	w.stop = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// the slice is replaced instead of modified, so a running notify
+		// keeps its copy.
+		watchers := slices.DeleteFunc(slices.Clone(s.watchers[key]), func(other *Watcher) bool {
+			return other == w
+		})
+		if len(watchers) == 0 {
+			delete(s.watchers, key)
+			return
+		}
+		s.watchers[key] = watchers
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchers[key] = append(slices.Clip(s.watchers[key]), w)
+	return w
+}
+
+// Clear removes all values and stops all watchers of the store.
+func (s *Store) Clear() {
+	s.mu.Lock()
+	watchers := s.watchers
+	s.data = make(map[any]any)
+	s.watchers = make(map[any][]*Watcher)
+	s.mu.Unlock()
+	for _, slot := range watchers {
+		for _, w := range slot {
+			w.active.Store(false)
+		}
+	}
+}
+
+func notify[T any](watchers []*Watcher, value T) {
+	for _, w := range watchers {
+		if w.active.Load() {
+			w.fn.(func(T))(value)
+		}
+	}
+}
+
+func addPremade[T any](value T, s *Store) {
+	s.data[slotKey[T]{}] = value
 }
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestNamedSlots(t *testing.T) {
+	s := New()
+	SetIn(s, "", 1)
+	SetIn(s, "lives", 3)
+	SetIn(s, "lives", "three")
+
+	if v, _ := GetIn[int](s, ""); v != 1 {
+		t.Errorf("unnamed slot should hold 1, got %v", v)
+	}
+	if v, _ := GetIn[int](s, "lives"); v != 3 {
+		t.Errorf("named slot should hold 3, got %v", v)
+	}
+	if v, _ := GetIn[string](s, "lives"); v != "three" {
+		t.Errorf("slots of different types should not collide, got %v", v)
+	}
+	DeleteIn[int](s, "lives")
+	if _, ok := GetIn[int](s, "lives"); ok {
+		t.Errorf("deleted slot should be empty")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	s := New()
+	seen := []int{}
+	w := WatchIn(s, "score", func(v int) { seen = append(seen, v) })
+	SetIn(s, "score", 10)
+	SetIn(s, "other", 99)
+	UpdateIn(s, "score", func(v int, found bool) int { return v + 5 })
+	w.Stop()
+	SetIn(s, "score", 0)
+	if !slices.Equal(seen, []int{10, 15}) {
+		t.Fatalf("want changes [10 15], got %v", seen)
+	}
+
+	WatchIn(s, "score", func(v int) { t.Error("cleared store should stop its watchers") })
+	s.Clear()
+	SetIn(s, "score", 1)
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	s := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				UpdateIn(s, "loaded", func(v int, found bool) int { return v + 1 })
+			}
+		}()
+	}
+	wg.Wait()
+	if v, _ := GetIn[int](s, "loaded"); v != 800 {
+		t.Errorf("updates were lost, got %v", v)
+	}
+}
@@ -1,6 +1,7 @@
 package gui
 
 import (
+	input "gorl/fw/core/input/input_handling"
 	"gorl/fw/core/logging"
 	"gorl/fw/util"
 	"fmt"
@@ -449,15 +450,21 @@ func doRecursiveDraw(container Container) {
 			backend_label_finalize(*w)
 		case *Button:
 			w.update_button()
+			consumeMouseOver(w.Bounds())
 			backend_button(*w)
 			backend_button_finalize(*w)
 		case *ScrollPanel:
 			w.update_scroll_panel()
+			consumeMouseOver(w.visible_bounds)
 			backend_scroll_panel(*w)
 			doRecursiveDraw(*w.container) // draw the panels children
 			backend_scroll_panel_finalize(*w)
 		case *Slider:
 			w.update()
+			consumeMouseOver(w.Bounds())
+			if w.is_dragging {
+				input.ConsumeMouse()
+			}
 			backend_slider(*w)
 			backend_slider_finalize(*w)
 		default:
@@ -465,3 +472,12 @@ func doRecursiveDraw(container Container) {
 		}
 	}
 }
+
+// consumeMouseOver keeps the mouse input of this frame from reaching the
+// entities if the cursor is over the bounds of a widget, so clicking a widget
+// does not also click whatever is behind it.
+func consumeMouseOver(bounds rl.Rectangle) {
+	if rl.CheckCollisionPointRec(rl.GetMousePosition(), bounds) {
+		input.ConsumeMouse()
+	}
+}
@@ -0,0 +1,187 @@
+package event
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrStopPropagation can be returned by a handler to stop the event from
+// reaching the handlers after it. Publish does not report it as an error.
+var ErrStopPropagation = errors.New("event propagation stopped")
+
+// Bus delivers typed events to the handlers subscribed to their type. Events
+// are plain values, usually structs, and each type is its own topic:
+//
+//	type PlayerDied struct{ Player int }
+//
+//	sub := event.Subscribe(func(e PlayerDied) error { ... })
+//	defer sub.Unsubscribe()
+//	event.Publish(PlayerDied{Player: 1})
+//
+// A bus is safe for concurrent use. Handlers may subscribe and unsubscribe
+// while an event is published. To publish an event later, e.g. because its
+// handlers change the entity tree, see QueueTo.
+type Bus struct {
+	mu     sync.RWMutex
+	topics map[any]any
+	queue  queue
+}
+
+// NewBus returns a new event bus.
+//
+// Use this to create a separate bus. Otherwise use the default bus by calling
+// Subscribe and Publish directly.
+func NewBus() *Bus {
+	return &Bus{topics: make(map[any]any)}
+}
+
+// topicKey identifies the topic of an event type, without reflection.
+type topicKey[T any] struct{}
+
+// topic holds the handlers of one event type, by descending priority.
+type topic[T any] struct {
+	handlers []*handler[T]
+}
+
+type handler[T any] struct {
+	fn       func(T) error
+	priority int
+	sub      *Subscription
+	// once handlers unsubscribe before they are called the first time.
+	once bool
+}
+
+// Subscription is the handle of a subscribed handler.
+type Subscription struct {
+	active      atomic.Bool
+	unsubscribe func()
+}
+
+// Unsubscribe removes the handler from the bus. It is not called for events
+// published afterwards, including the rest of an event being published right
+// now. Calling it more than once has no effect.
+func (s *Subscription) Unsubscribe() {
+	if s != nil && s.active.Swap(false) {
+		s.unsubscribe()
+	}
+}
+
+// IsActive returns true until the subscription is unsubscribed.
+func (s *Subscription) IsActive() bool {
+	return s != nil && s.active.Load()
+}
+
+// SubscribeTo registers a handler for events of type T on the bus, with
+// priority 0.
+func SubscribeTo[T any](bus *Bus, fn func(T) error) *Subscription {
+	return SubscribeToWithPriority(bus, 0, fn)
+}
+
+// SubscribeToWithPriority registers a handler for events of type T on the
+// bus. Handlers with a higher priority are called first, handlers with the
+// same priority in the order they subscribed.
+func SubscribeToWithPriority[T any](bus *Bus, priority int, fn func(T) error) *Subscription {
+	return subscribe(bus, &handler[T]{fn: fn, priority: priority})
+}
+
+// SubscribeOnceTo registers a handler for the next event of type T on the
+// bus only, with priority 0. It is unsubscribed before it is called.
+func SubscribeOnceTo[T any](bus *Bus, fn func(T) error) *Subscription {
+	return subscribe(bus, &handler[T]{fn: fn, once: true})
+}
+
+func subscribe[T any](bus *Bus, h *handler[T]) *Subscription {
+	if h.fn == nil {
+		panic("event: subscribed handler is nil")
+	}
+	priority := h.priority
+	h.sub = &Subscription{}
+	h.sub.active.Store(true)
+	h.sub.unsubscribe = func() { removeHandler(bus, h) }
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	t := topicOf[T](bus, true)
+	// insert after all handlers with the same or a higher priority. the slice
+	// is replaced instead of modified, so a running publish keeps its copy.
+	idx, _ := slices.BinarySearchFunc(t.handlers, priority, func(h *handler[T], priority int) int {
+		if h.priority >= priority {
+			return -1
+		}
+		return 1
+	})
+	t.handlers = slices.Insert(slices.Clip(t.handlers), idx, h)
+	return h.sub
+}
+
+// PublishTo sends the event to the handlers of its type on the bus, from the
+// highest priority to the lowest. It stops at the first handler returning an
+// error and returns that error, unless it is ErrStopPropagation.
+func PublishTo[T any](bus *Bus, event T) error {
+	bus.mu.RLock()
+	t := topicOf[T](bus, false)
+	var handlers []*handler[T]
+	if t != nil {
+		handlers = t.handlers
+	}
+	bus.mu.RUnlock()
+
+	for _, h := range handlers {
+		if h.once {
+			// only the first publish to get here calls the handler.
+			if !h.sub.active.Swap(false) {
+				continue
+			}
+			h.sub.unsubscribe()
+		} else if !h.sub.IsActive() {
+			continue
+		}
+		if err := h.fn(event); err != nil {
+			if errors.Is(err, ErrStopPropagation) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// HasSubscribersOn returns true if any handler is subscribed to events of
+// type T on the bus.
+func HasSubscribersOn[T any](bus *Bus) bool {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+	t := topicOf[T](bus, false)
+	return t != nil && len(t.handlers) > 0
+}
+
+// topicOf returns the topic of T, creating it if create is set. The caller
+// must hold the lock of the bus.
+func topicOf[T any](bus *Bus, create bool) *topic[T] {
+	t, ok := bus.topics[topicKey[T]{}]
+	if !ok {
+		if !create {
+			return nil
+		}
+		t = &topic[T]{}
+		bus.topics[topicKey[T]{}] = t
+	}
+	return t.(*topic[T])
+}
+
+func removeHandler[T any](bus *Bus, h *handler[T]) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	t := topicOf[T](bus, false)
+	if t == nil {
+		return
+	}
+	t.handlers = slices.DeleteFunc(slices.Clone(t.handlers), func(other *handler[T]) bool {
+		return other == h
+	})
+	if len(t.handlers) == 0 {
+		delete(bus.topics, topicKey[T]{})
+	}
+}
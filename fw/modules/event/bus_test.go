@@ -0,0 +1,187 @@
+package event
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+type scored struct{ points int }
+type otherEvent struct{}
+
+func TestBusPriorityAndStop(t *testing.T) {
+	bus := NewBus()
+	calls := []string{}
+	record := func(name string, err error) func(scored) error {
+		return func(scored) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	SubscribeTo(bus, record("a", nil))
+	SubscribeToWithPriority(bus, 10, record("high", nil))
+	SubscribeTo(bus, record("b", nil))
+	SubscribeToWithPriority(bus, -1, record("low", nil))
+	SubscribeTo(bus, func(otherEvent) error {
+		t.Error("handler of another type should not be called")
+		return nil
+	})
+
+	if err := PublishTo(bus, scored{1}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"high", "a", "b", "low"}; !slices.Equal(calls, want) {
+		t.Fatalf("want order %v, got %v", want, calls)
+	}
+
+	calls = nil
+	SubscribeToWithPriority(bus, 5, record("stop", ErrStopPropagation))
+	if err := PublishTo(bus, scored{1}); err != nil {
+		t.Fatalf("stopping should not be an error, got %v", err)
+	}
+	if want := []string{"high", "stop"}; !slices.Equal(calls, want) {
+		t.Fatalf("want %v, got %v", want, calls)
+	}
+
+	fail := errors.New("fail")
+	SubscribeToWithPriority(bus, 20, record("fail", fail))
+	if err := PublishTo(bus, scored{1}); err != fail {
+		t.Fatalf("want the handler's error, got %v", err)
+	}
+}
+
+func TestBusUnsubscribe(t *testing.T) {
+	bus := NewBus()
+	count := 0
+	var second *Subscription
+	// the first handler removes the second while the event is published.
+	first := SubscribeTo(bus, func(scored) error {
+		second.Unsubscribe()
+		return nil
+	})
+	second = SubscribeTo(bus, func(scored) error {
+		count++
+		return nil
+	})
+
+	PublishTo(bus, scored{1})
+	if count != 0 || second.IsActive() {
+		t.Fatalf("unsubscribed handler was called %v times", count)
+	}
+	first.Unsubscribe()
+	first.Unsubscribe()
+	if HasSubscribersOn[scored](bus) {
+		t.Errorf("bus should have no subscribers left")
+	}
+}
+
+func TestDispatcherStopPropagation(t *testing.T) {
+	d := NewDispatcher()
+	calls := 0
+	d.Listen("score", func(points int) error {
+		calls++
+		return nil
+	})
+	d.Listen("score", func(points int) error {
+		calls++
+		return ErrStopPropagation
+	})
+	if err := d.Trigger("score", 1); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("listeners after the stopping one should not be called, got %v calls", calls)
+	}
+}
+
+func BenchmarkBusPublish(b *testing.B) {
+	bus := NewBus()
+	total := 0
+	for i := 0; i < 4; i++ {
+		SubscribeTo(bus, func(e scored) error {
+			total += e.points
+			return nil
+		})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		PublishTo(bus, scored{1})
+	}
+}
+
+func BenchmarkDispatcherTrigger(b *testing.B) {
+	d := NewDispatcher()
+	total := 0
+	for i := 0; i < 4; i++ {
+		d.Listen("score", func(points int) error {
+			total += points
+			return nil
+		})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Trigger("score", 1)
+	}
+}
+
+func TestBusQueueAndDelay(t *testing.T) {
+	bus := NewBus()
+	received := []int{}
+	SubscribeTo(bus, func(e scored) error {
+		received = append(received, e.points)
+		if e.points == 1 {
+			// queued while flushing, so it waits for the next flush.
+			QueueTo(bus, scored{2})
+		}
+		return nil
+	})
+
+	QueueTo(bus, scored{1})
+	PublishAfterFramesTo(bus, 1, scored{10})
+	PublishAfterTo(bus, 0.25, scored{20})
+	PublishAfterTo(bus, 0.1, scored{30}).Cancel()
+	if len(received) != 0 {
+		t.Fatalf("queued events should wait for the flush, got %v", received)
+	}
+
+	want := [][]int{{1}, {1, 2, 10}, {1, 2, 10, 20}}
+	for frame, want := range want {
+		if err := bus.Flush(0.1); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(received, want) {
+			t.Fatalf("after flush %v want %v, got %v", frame, want, received)
+		}
+	}
+}
+
+func TestBusSubscribeOnce(t *testing.T) {
+	bus := NewBus()
+	calls := 0
+	SubscribeOnceTo(bus, func(scored) error {
+		calls++
+		// publishing from the handler must not call it again.
+		return PublishTo(bus, scored{})
+	})
+	PublishTo(bus, scored{})
+	PublishTo(bus, scored{})
+	if calls != 1 || HasSubscribersOn[scored](bus) {
+		t.Errorf("one-shot handler was called %v times", calls)
+	}
+}
+
+func TestDispatcherListenFromListener(t *testing.T) {
+	d := NewDispatcher()
+	d.Listen("spawn", func() error {
+		// would deadlock if the listeners were called holding the lock.
+		return d.Listen("spawned", func() error { return nil })
+	})
+	if err := d.Trigger("spawn"); err != nil {
+		t.Fatal(err)
+	}
+	if !d.HasEvent("spawned") {
+		t.Errorf("listener registered from a listener is missing")
+	}
+}
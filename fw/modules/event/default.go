@@ -32,3 +32,66 @@ func ListEvents() []string {
 func RemoveEvents(names ...string) {
 	defaultDispatcher.RemoveEvents(names...)
 }
+
+// Default event bus instance
+var defaultBus = NewBus()
+
+// Subscribe registers a handler for events of type T, with priority 0.
+// (Uses the default bus)
+func Subscribe[T any](fn func(T) error) *Subscription {
+	return SubscribeTo(defaultBus, fn)
+}
+
+// SubscribeWithPriority registers a handler for events of type T. Handlers
+// with a higher priority are called first.
+// (Uses the default bus)
+func SubscribeWithPriority[T any](priority int, fn func(T) error) *Subscription {
+	return SubscribeToWithPriority(defaultBus, priority, fn)
+}
+
+// SubscribeOnce registers a handler for the next event of type T only.
+// (Uses the default bus)
+func SubscribeOnce[T any](fn func(T) error) *Subscription {
+	return SubscribeOnceTo(defaultBus, fn)
+}
+
+// Publish sends the event to the handlers of its type.
+// (Uses the default bus)
+func Publish[T any](event T) error {
+	return PublishTo(defaultBus, event)
+}
+
+// HasSubscribers returns true if any handler is subscribed to events of type
+// T.
+// (Uses the default bus)
+func HasSubscribers[T any]() bool {
+	return HasSubscribersOn[T](defaultBus)
+}
+
+// Queue adds the event to the queue, to be published at the end of the frame.
+// (Uses the default bus)
+func Queue[T any](event T) {
+	QueueTo(defaultBus, event)
+}
+
+// PublishAfter publishes the event at the end of the frame in which the delay
+// in seconds has passed.
+// (Uses the default bus)
+func PublishAfter[T any](seconds float32, event T) *Scheduled {
+	return PublishAfterTo(defaultBus, seconds, event)
+}
+
+// PublishAfterFrames publishes the event at the end of the frame the given
+// number of frames later.
+// (Uses the default bus)
+func PublishAfterFrames[T any](frames int, event T) *Scheduled {
+	return PublishAfterFramesTo(defaultBus, frames, event)
+}
+
+// Flush publishes the queued events and the delayed events that are due,
+// after advancing the delays by a frame of dt seconds. The game loop calls it
+// once per frame.
+// (Uses the default bus)
+func Flush(dt float32) error {
+	return defaultBus.Flush(dt)
+}
@@ -7,7 +7,8 @@ import (
 	"sync"
 )
 
-// dispatcher is a dispatcher with a map of events and corresponding listeners
+// dispatcher is a dispatcher with a map of events and corresponding listeners.
+// It checks and calls the listeners with reflection, prefer the typed Bus.
 type dispatcher struct {
 	sync.RWMutex
 
@@ -15,7 +16,8 @@ type dispatcher struct {
 }
 
 // EventHandler is a function that can be registered as an event listener.
-// It must be a function that returns an error and nothing else.
+// It must be a function that returns an error and nothing else. Returning
+// ErrStopPropagation stops the event from reaching the listeners after it.
 type EventHandler any
 
 type Dispatcher interface {
@@ -68,12 +70,14 @@ func (e *dispatcher) Listen(name string, fn EventHandler) error {
 	return nil
 }
 
-// Trigger fires an event by name and passes the given parameters to the listeners
+// Trigger fires an event by name and passes the given parameters to the listeners.
+// The listeners are called without holding the lock, so they may listen to
+// events themselves.
 func (e *dispatcher) Trigger(name string, params ...any) error {
 	e.RLock()
-	defer e.RUnlock()
-
 	fns := e.events[name]
+	e.RUnlock()
+
 	for i := len(fns) - 1; i >= 0; i-- {
 		stopped, err := e.call(fns[i], params...)
 		if err != nil {
@@ -109,11 +113,7 @@ func (e *dispatcher) call(fn EventHandler, params ...any) (stopped bool, err err
 		}
 		in = append(in, s)
 
-		result := f.CallSlice(in)[0].Interface()
-		if err, ok := result.(error); ok && err != nil {
-			return stopped, err
-		}
-		return stopped, nil
+		return callResult(f.CallSlice(in)[0].Interface())
 	}
 
 	if len(params) != numIn {
@@ -123,11 +123,19 @@ func (e *dispatcher) call(fn EventHandler, params ...any) (stopped bool, err err
 		in = append(in, reflect.ValueOf(param))
 	}
 
-	result := f.Call(in)[0].Interface()
+	return callResult(f.Call(in)[0].Interface())
+}
+
+// callResult interprets the value returned by a listener. Returning
+// ErrStopPropagation stops the event without reporting an error.
+func callResult(result any) (stopped bool, err error) {
 	if err, ok := result.(error); ok && err != nil {
-		return stopped, err
+		if errors.Is(err, ErrStopPropagation) {
+			return true, nil
+		}
+		return false, err
 	}
-	return stopped, nil
+	return false, nil
 }
 
 // Helper function to list expected parameter types
@@ -0,0 +1,101 @@
+package event
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// queue holds the events of a bus that wait for the next Flush.
+type queue struct {
+	sync.Mutex
+	// ready are delivered on the next flush.
+	ready []func() error
+	// delayed are moved to ready once their delay is over.
+	delayed []*Scheduled
+}
+
+// Scheduled is the handle of a delayed event.
+type Scheduled struct {
+	cancelled atomic.Bool
+	seconds   float32
+	frames    int
+	publish   func() error
+}
+
+// Cancel keeps the event from being published, if it was not published yet.
+func (s *Scheduled) Cancel() {
+	if s != nil {
+		s.cancelled.Store(true)
+	}
+}
+
+// QueueTo adds the event to the queue of the bus. It is published on the
+// next call to Flush, e.g. at the end of the frame, instead of right away.
+func QueueTo[T any](bus *Bus, event T) {
+	bus.queue.Lock()
+	defer bus.queue.Unlock()
+	bus.queue.ready = append(bus.queue.ready, func() error { return PublishTo(bus, event) })
+}
+
+// PublishAfterTo publishes the event on the first Flush of the bus after the
+// delay in seconds has passed.
+func PublishAfterTo[T any](bus *Bus, seconds float32, event T) *Scheduled {
+	return schedule(bus, &Scheduled{seconds: seconds, publish: func() error { return PublishTo(bus, event) }})
+}
+
+// PublishAfterFramesTo publishes the event on the Flush of the bus the given
+// number of frames later. With 0 frames, this is the same as QueueTo.
+func PublishAfterFramesTo[T any](bus *Bus, frames int, event T) *Scheduled {
+	return schedule(bus, &Scheduled{frames: frames, publish: func() error { return PublishTo(bus, event) }})
+}
+
+func schedule(bus *Bus, s *Scheduled) *Scheduled {
+	bus.queue.Lock()
+	defer bus.queue.Unlock()
+	bus.queue.delayed = append(bus.queue.delayed, s)
+	return s
+}
+
+// Flush advances the delayed events of the bus by one frame of dt seconds,
+// and publishes the queued events and the delayed events that are due, in the
+// order they were queued. Events queued while flushing are published on the
+// next flush. Flush does not stop at errors, it returns all of them joined.
+//
+// The game loop flushes the default bus once per frame, see Flush.
+func (bus *Bus) Flush(dt float32) error {
+	bus.queue.Lock()
+	waiting := bus.queue.delayed[:0]
+	for _, s := range bus.queue.delayed {
+		if s.cancelled.Load() {
+			continue
+		}
+		s.seconds -= dt
+		s.frames--
+		if s.seconds <= 0 && s.frames < 0 {
+			bus.queue.ready = append(bus.queue.ready, s.publishUnlessCancelled)
+			continue
+		}
+		waiting = append(waiting, s)
+	}
+	clear(bus.queue.delayed[len(waiting):])
+	bus.queue.delayed = waiting
+	ready := bus.queue.ready
+	bus.queue.ready = nil
+	bus.queue.Unlock()
+
+	var errs []error
+	for _, publish := range ready {
+		if err := publish(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scheduled) publishUnlessCancelled() error {
+	if s.cancelled.Load() {
+		return nil
+	}
+	return s.publish()
+}
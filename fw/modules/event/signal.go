@@ -0,0 +1,192 @@
+package event
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+)
+
+// Signal is an event declared on an entity, e.g. the Pressed signal of a
+// button or the Died signal of a health component. Other entities connect to
+// it, and the connection is removed once either side is removed from the gem:
+//
+//	type ButtonEntity struct {
+//		*entities.Entity
+//		Pressed *event.Signal[Pressed]
+//	}
+//
+//	button.Pressed = event.NewSignal[Pressed](button)
+//	button.Pressed.Connect(door, func(e Pressed) error { ... })
+//	button.Pressed.Emit(Pressed{})
+//
+// A signal is safe for concurrent use. Handlers may connect and disconnect
+// while the signal is emitted.
+type Signal[T any] struct {
+	owner entities.IEntity
+
+	mu    sync.RWMutex
+	slots []*slot[T]
+}
+
+type slot[T any] struct {
+	fn   func(T) error
+	conn *Connection
+}
+
+// NewSignal returns a new signal owned by the given entity. Its connections
+// are removed once the owner is removed from the gem.
+func NewSignal[T any](owner entities.IEntity) *Signal[T] {
+	return &Signal[T]{owner: owner}
+}
+
+// Connect registers a handler for the signal, owned by the listener. The
+// connection is removed once the listener or the owner of the signal is
+// removed from the gem. The listener may be nil, then only the owner is
+// considered.
+//
+// Handlers are called in the order they connected.
+func (s *Signal[T]) Connect(listener entities.IEntity, fn func(T) error) *Connection {
+	if fn == nil {
+		panic("event: connected handler is nil")
+	}
+	sl := &slot[T]{fn: fn, conn: &Connection{}}
+	conn := sl.conn
+	conn.active.Store(true)
+	conn.entities = [2]entities.IEntity{s.owner, listener}
+	conn.disconnect = func() {
+		s.removeSlot(sl)
+		untrack(conn)
+	}
+
+	s.mu.Lock()
+	// the slice is replaced instead of modified, so a running emit keeps its
+	// copy.
+	s.slots = append(slices.Clip(s.slots), sl)
+	s.mu.Unlock()
+	track(conn)
+	return conn
+}
+
+// Emit calls the connected handlers with the value. Like PublishTo, it stops
+// at the first handler returning an error and returns that error, unless it
+// is ErrStopPropagation.
+func (s *Signal[T]) Emit(value T) error {
+	s.mu.RLock()
+	slots := s.slots
+	s.mu.RUnlock()
+
+	for _, sl := range slots {
+		if !sl.conn.IsConnected() {
+			continue
+		}
+		if err := sl.fn(value); err != nil {
+			if errors.Is(err, ErrStopPropagation) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// HasConnections returns true if any handler is connected to the signal.
+func (s *Signal[T]) HasConnections() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.slots) > 0
+}
+
+// DisconnectAll removes all connections of the signal.
+func (s *Signal[T]) DisconnectAll() {
+	s.mu.RLock()
+	slots := s.slots
+	s.mu.RUnlock()
+	for _, sl := range slots {
+		sl.conn.Disconnect()
+	}
+}
+
+func (s *Signal[T]) removeSlot(sl *slot[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.slots = slices.DeleteFunc(slices.Clone(s.slots), func(other *slot[T]) bool {
+		return other == sl
+	})
+}
+
+// Connection is the handle of a handler connected to a signal.
+type Connection struct {
+	active     atomic.Bool
+	entities   [2]entities.IEntity // the owner of the signal and the listener
+	disconnect func()
+}
+
+// Disconnect removes the handler from the signal. It is not called for values
+// emitted afterwards, including the rest of a running emit. Calling it more
+// than once has no effect.
+func (c *Connection) Disconnect() {
+	if c != nil && c.active.Swap(false) {
+		c.disconnect()
+	}
+}
+
+// IsConnected returns true until the connection is disconnected.
+func (c *Connection) IsConnected() bool {
+	return c != nil && c.active.Load()
+}
+
+// connections holds the connections of each entity, so they can be
+// disconnected once the entity is removed from the gem.
+var connections = struct {
+	sync.Mutex
+	hooked   bool
+	byEntity map[entities.IEntity][]*Connection
+}{byEntity: make(map[entities.IEntity][]*Connection)}
+
+func track(conn *Connection) {
+	connections.Lock()
+	defer connections.Unlock()
+	if !connections.hooked {
+		gem.OnRemove(disconnectEntity)
+		connections.hooked = true
+	}
+	for i, entity := range conn.entities {
+		// an entity connected to its own signal is tracked once.
+		if entity == nil || (i == 1 && entity == conn.entities[0]) {
+			continue
+		}
+		connections.byEntity[entity] = append(connections.byEntity[entity], conn)
+	}
+}
+
+func untrack(conn *Connection) {
+	connections.Lock()
+	defer connections.Unlock()
+	for _, entity := range conn.entities {
+		conns, ok := connections.byEntity[entity]
+		if !ok {
+			continue
+		}
+		conns = slices.DeleteFunc(conns, func(other *Connection) bool { return other == conn })
+		if len(conns) == 0 {
+			delete(connections.byEntity, entity)
+			continue
+		}
+		connections.byEntity[entity] = conns
+	}
+}
+
+// disconnectEntity removes all connections of an entity removed from the gem.
+func disconnectEntity(entity entities.IEntity) {
+	connections.Lock()
+	conns := connections.byEntity[entity]
+	delete(connections.byEntity, entity)
+	connections.Unlock()
+	for _, conn := range conns {
+		conn.Disconnect()
+	}
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"testing"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type pressed struct{}
+
+func newTestEntity(name string) *entities.Entity {
+	ent := entities.NewEntity(name, rl.Vector2Zero(), 0, rl.Vector2One())
+	gem.Append(gem.GetRoot(), ent)
+	return ent
+}
+
+func TestSignalDisconnectsRemovedEntities(t *testing.T) {
+	gem.Init()
+	button, door, lamp := newTestEntity("button"), newTestEntity("door"), newTestEntity("lamp")
+	signal := NewSignal[pressed](button)
+	calls := map[string]int{}
+	signal.Connect(door, func(pressed) error { calls["door"]++; return nil })
+	signal.Connect(lamp, func(pressed) error { calls["lamp"]++; return nil })
+
+	signal.Emit(pressed{})
+	gem.Remove(door)
+	signal.Emit(pressed{})
+	if calls["door"] != 1 || calls["lamp"] != 2 {
+		t.Fatalf("removed listener should be disconnected, got %v", calls)
+	}
+
+	// removing the owner disconnects the remaining listeners and forgets them.
+	gem.Remove(button)
+	if signal.HasConnections() || len(connections.byEntity) != 0 {
+		t.Fatalf("connections left after the owner was removed: %v", connections.byEntity)
+	}
+	gem.Deinit()
+}
+
+func TestSignalDisconnect(t *testing.T) {
+	signal := NewSignal[pressed](nil)
+	calls := 0
+	var second *Connection
+	first := signal.Connect(nil, func(pressed) error {
+		second.Disconnect()
+		return nil
+	})
+	second = signal.Connect(nil, func(pressed) error {
+		calls++
+		return nil
+	})
+	signal.Emit(pressed{})
+	first.Disconnect()
+	if calls != 0 || signal.HasConnections() {
+		t.Errorf("disconnected handler was called %v times", calls)
+	}
+}
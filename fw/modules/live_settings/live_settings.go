@@ -0,0 +1,109 @@
+package live_settings
+
+import (
+	"gorl/fw/audio"
+	"gorl/fw/core/backend"
+	input "gorl/fw/core/input/input_handling"
+	"gorl/fw/core/render"
+	"gorl/fw/core/settings"
+	"gorl/fw/core/store"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// applier applies the settings to the window, the renderer and the audio.
+type applier struct {
+	watcher *store.Watcher
+	// crtShader is the shader of the crt effect while it is enabled.
+	crtShader *rl.Shader
+}
+
+var applierInstance applier
+
+// Init applies the current settings, and applies them again whenever they
+// change, e.g. by settings.Modify or a hot reload. Call it once the backend
+// was initialized, before the first cameras are created.
+func Init() {
+	apply(nil, settings.CurrentSettings())
+	applierInstance.watcher = settings.Watch(apply)
+}
+
+// Deinit stops applying the settings and removes the crt effect.
+func Deinit() {
+	applierInstance.watcher.Stop()
+	setCrtEffect(false)
+}
+
+// apply applies the settings that differ from old. If old is nil, all
+// settings that differ from the zero value are applied.
+func apply(old, new *settings.GameSettings) {
+	if old == nil {
+		old = &settings.GameSettings{}
+	}
+	b := backend.Current()
+
+	// leave fullscreen before resizing the window, and enter it afterwards,
+	// so the monitor is switched to the new size.
+	if old.Fullscreen && !new.Fullscreen {
+		b.SetFullscreen(false)
+	}
+	if old.ScreenWidth != new.ScreenWidth || old.ScreenHeight != new.ScreenHeight {
+		b.SetWindowSize(rl.NewVector2(float32(new.ScreenWidth), float32(new.ScreenHeight)))
+	}
+	if new.Fullscreen && !old.Fullscreen {
+		b.SetFullscreen(true)
+	}
+	if old.VSync != new.VSync {
+		b.SetVSync(new.VSync)
+	}
+	if old.TargetFps != new.TargetFps {
+		b.SetTargetFps(int32(new.TargetFps))
+	}
+
+	if old.RenderWidth != new.RenderWidth || old.RenderHeight != new.RenderHeight {
+		render.SetScreenSize(rl.NewVector2(float32(new.RenderWidth), float32(new.RenderHeight)))
+	}
+	if old.IntegerScaling != new.IntegerScaling {
+		render.SetIntegerScaling(new.IntegerScaling)
+	}
+	if old.EnableCrtEffect != new.EnableCrtEffect {
+		setCrtEffect(new.EnableCrtEffect)
+	}
+
+	audio.SetGlobalVolume(new.SoundVolume)
+	input.SetGamepadEnabled(new.EnableGamepad)
+}
+
+// setCrtEffect adds or removes the crt shader of every camera.
+func setCrtEffect(enabled bool) {
+	// without a GPU there are no shaders.
+	if render.IsHeadless() || enabled == (applierInstance.crtShader != nil) {
+		return
+	}
+	if !enabled {
+		render.RemoveGlobalShader(applierInstance.crtShader)
+		rl.UnloadShader(*applierInstance.crtShader)
+		applierInstance.crtShader = nil
+		return
+	}
+	shader := rl.LoadShaderFromMemory("", crtShader)
+	applierInstance.crtShader = &shader
+	render.AddGlobalShader(applierInstance.crtShader)
+}
+
+const crtShader = `#version 330
+in vec2 fragTexCoord;
+in vec4 fragColor;
+uniform sampler2D texture0;
+uniform vec4 colDiffuse;
+out vec4 finalColor;
+
+void main() {
+    vec4 texel = texture(texture0, fragTexCoord) * colDiffuse * fragColor;
+    // darken every other line of pixels, and the corners of the screen.
+    float scanline = 0.85 + 0.15 * step(1.0, mod(gl_FragCoord.y, 2.0));
+    vec2 centered = fragTexCoord * 2.0 - 1.0;
+    float vignette = 1.0 - 0.25 * dot(centered, centered);
+    finalColor = vec4(texel.rgb * scanline * vignette, texel.a);
+}
+`
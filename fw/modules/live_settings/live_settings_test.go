@@ -0,0 +1,40 @@
+package live_settings
+
+import (
+	"testing"
+
+	"gorl/fw/audio"
+	"gorl/fw/core/backend"
+	"gorl/fw/core/render"
+	"gorl/fw/core/settings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestSettingsAreApplied(t *testing.T) {
+	b := backend.NewHeadlessBackend(0.25)
+	backend.Use(b)
+	b.Init("test", rl.NewVector2(800, 600), 4)
+	settings.UseFallbackSettings()
+
+	Init()
+	defer Deinit()
+	if render.GetScreenSize() != rl.NewVector2(1920, 1080) || audio.GetGlobalVolume() != 0.5 {
+		t.Fatalf("initial settings not applied, screen %v, volume %v", render.GetScreenSize(), audio.GetGlobalVolume())
+	}
+
+	err := settings.Modify(func(s *settings.GameSettings) {
+		s.RenderWidth, s.RenderHeight = 320, 180
+		s.ScreenWidth, s.ScreenHeight = 640, 480
+		s.SoundVolume = 0.2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if render.GetScreenSize() != rl.NewVector2(320, 180) || audio.GetGlobalVolume() != 0.2 {
+		t.Errorf("changed settings not applied, screen %v, volume %v", render.GetScreenSize(), audio.GetGlobalVolume())
+	}
+	if rect := render.GetPresentationRect(); rect != rl.NewRectangle(0, 60, 640, 360) {
+		t.Errorf("screen should be letterboxed in the resized window, got %v", rect)
+	}
+}
@@ -0,0 +1,77 @@
+package picking
+
+import (
+	"gorl/fw/collision"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/solarlune/resolv"
+)
+
+// HitShape is the area of an entity that can be picked with the pointer.
+type HitShape interface {
+	// ContainsPoint returns true if the point is inside the shape. The point
+	// is given in the local space of the entity, and in world space.
+	ContainsPoint(local, world rl.Vector2) bool
+}
+
+// Pickable is implemented by entities that can be picked with the pointer.
+type Pickable interface {
+	GetHitShape() HitShape
+}
+
+// HitCircle is a circle in the local space of the entity.
+type HitCircle struct {
+	Center rl.Vector2
+	Radius float32
+}
+
+func (c HitCircle) ContainsPoint(local, world rl.Vector2) bool {
+	return rl.CheckCollisionPointCircle(local, c.Center, c.Radius)
+}
+
+// HitRect is a rectangle in the local space of the entity.
+type HitRect struct {
+	Rect rl.Rectangle
+}
+
+func (r HitRect) ContainsPoint(local, world rl.Vector2) bool {
+	return rl.CheckCollisionPointRec(local, r.Rect)
+}
+
+// HitPolygon is a polygon in the local space of the entity.
+type HitPolygon struct {
+	Points []rl.Vector2
+}
+
+func (p HitPolygon) ContainsPoint(local, world rl.Vector2) bool {
+	return rl.CheckCollisionPointPoly(local, p.Points)
+}
+
+// HitShapes combines several shapes, the point has to be inside any of
+// them.
+type HitShapes []HitShape
+
+func (s HitShapes) ContainsPoint(local, world rl.Vector2) bool {
+	for _, shape := range s {
+		if shape.ContainsPoint(local, world) {
+			return true
+		}
+	}
+	return false
+}
+
+// HitCollider uses the shape of a collider, which is in world space.
+type HitCollider struct {
+	Collider collision.Collider
+}
+
+func (c HitCollider) ContainsPoint(local, world rl.Vector2) bool {
+	point := []float64{float64(world.X), float64(world.Y)}
+	switch shape := c.Collider.GetResolvShape().(type) {
+	case *resolv.ConvexPolygon:
+		return shape.PointInside(point)
+	case *resolv.Circle:
+		return shape.PointInside(point)
+	}
+	return false
+}
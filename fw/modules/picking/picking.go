@@ -0,0 +1,235 @@
+package picking
+
+import (
+	"math"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	input_event "gorl/fw/core/input/input_event"
+	input "gorl/fw/core/input/input_handling"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PointerEvent describes the pointer relative to a picked entity.
+type PointerEvent struct {
+	Entity entities.IEntity
+	// Camera is the camera the entity was picked through.
+	Camera input_event.Viewport
+
+	ScreenPosition rl.Vector2
+	WorldPosition  rl.Vector2
+	// LocalPosition is the pointer in the local space of the entity.
+	LocalPosition rl.Vector2
+	// PressPosition and PressLocalPosition are where the button was pressed,
+	// in world and local space, for clicks and drags.
+	PressPosition      rl.Vector2
+	PressLocalPosition rl.Vector2
+}
+
+// Pickable entities can implement any of these to receive pointer events.
+type (
+	// PointerEnterHandler is called when the pointer starts hovering the
+	// entity.
+	PointerEnterHandler interface {
+		OnPointerEnter(event *PointerEvent)
+	}
+	// PointerExitHandler is called when the pointer stops hovering the
+	// entity.
+	PointerExitHandler interface {
+		OnPointerExit(event *PointerEvent)
+	}
+	// PointerHoverHandler is called every frame the pointer hovers the
+	// entity.
+	PointerHoverHandler interface {
+		OnPointerHover(event *PointerEvent)
+	}
+	// ClickHandler is called when the button was pressed and released on
+	// the entity without dragging it.
+	ClickHandler interface {
+		OnClick(event *PointerEvent)
+	}
+	// DragHandler is called when the button was pressed on the entity and
+	// the pointer moved further than the drag threshold while it is held.
+	// OnDrag is called every frame of the drag, even if the pointer left the
+	// entity.
+	DragHandler interface {
+		OnDragStart(event *PointerEvent)
+		OnDrag(event *PointerEvent)
+		OnDragEnd(event *PointerEvent)
+	}
+)
+
+// picker holds the state of the picking service between frames.
+type picker struct {
+	hovered       entities.IEntity
+	hoveredCamera input_event.Viewport
+
+	pressed       entities.IEntity
+	pressedCamera input_event.Viewport
+	pressPosition rl.Vector2
+	dragging      bool
+
+	dragThreshold float32
+}
+
+var pickerInstance = picker{dragThreshold: 4}
+
+func init() {
+	gem.OnRemove(forget)
+}
+
+// forget drops a removed entity from the picking state, so it gets no more
+// pointer events, not even OnPointerExit or OnDragEnd.
+func forget(entity entities.IEntity) {
+	p := &pickerInstance
+	if p.hovered == entity {
+		p.hovered, p.hoveredCamera = nil, nil
+	}
+	if p.pressed == entity {
+		p.pressed, p.pressedCamera = nil, nil
+		p.dragging = false
+	}
+}
+
+// SetDragThreshold sets how far in screen pixels the pointer has to move
+// while the button is held before a drag starts. With 0, drags start as
+// soon as the button is pressed.
+func SetDragThreshold(threshold float32) {
+	pickerInstance.dragThreshold = threshold
+}
+
+// GetHovered returns the entity under the pointer in the last frame, or nil.
+func GetHovered() entities.IEntity {
+	return pickerInstance.hovered
+}
+
+// Update resolves the pointer to the front-most pickable entity and sends
+// the pointer events. It reads the polled input of player 0, so it must run
+// after input.HandleInputEvents, with the same receivers. Pointer input
+// consumed by the gui or disabled by the input context does not reach any
+// entity, and neither do entities that don't process input, or all but the
+// focused one while a receiver holds the focus.
+func Update(inputReceivers []input.InputReceiver, drawn []input.DrawnReceiver) {
+	p := &pickerInstance
+	cursor := input.GetCursorPosition()
+	drawn = input.GetInputTargets(inputReceivers, drawn)
+
+	var hit entities.IEntity
+	var hitCamera input_event.Viewport
+	if input.IsActionActive(input_event.ActionMouseHover) {
+		hit, hitCamera = pick(cursor, drawn)
+	}
+
+	if hit != p.hovered {
+		if handler, ok := p.hovered.(PointerExitHandler); ok {
+			handler.OnPointerExit(p.newEvent(p.hovered, p.hoveredCamera, cursor))
+		}
+		if handler, ok := hit.(PointerEnterHandler); ok {
+			handler.OnPointerEnter(p.newEvent(hit, hitCamera, cursor))
+		}
+		p.hovered, p.hoveredCamera = hit, hitCamera
+	}
+	if handler, ok := hit.(PointerHoverHandler); ok {
+		handler.OnPointerHover(p.newEvent(hit, hitCamera, cursor))
+	}
+
+	if input.IsActionActive(input_event.ActionClickDown) && hit != nil {
+		p.pressed, p.pressedCamera = hit, hitCamera
+		p.pressPosition = cursor
+		p.dragging = false
+	}
+	if p.pressed == nil {
+		return
+	}
+
+	if input.IsActionActive(input_event.ActionClickHeld) {
+		handler, ok := p.pressed.(DragHandler)
+		if !ok {
+			return
+		}
+		if !p.dragging && rl.Vector2Distance(cursor, p.pressPosition) >= p.dragThreshold {
+			p.dragging = true
+			handler.OnDragStart(p.newEvent(p.pressed, p.pressedCamera, cursor))
+		}
+		if p.dragging {
+			handler.OnDrag(p.newEvent(p.pressed, p.pressedCamera, cursor))
+		}
+		return
+	}
+
+	// the button is up again, or its input is not available anymore.
+	if handler, ok := p.pressed.(DragHandler); ok && p.dragging {
+		handler.OnDragEnd(p.newEvent(p.pressed, p.pressedCamera, cursor))
+	} else if handler, ok := p.pressed.(ClickHandler); ok && hit == p.pressed &&
+		input.IsActionActive(input_event.ActionClickUp) {
+		handler.OnClick(p.newEvent(p.pressed, p.pressedCamera, cursor))
+	}
+	p.pressed, p.pressedCamera = nil, nil
+	p.dragging = false
+}
+
+// pick returns the front-most pickable entity under the cursor, and the
+// camera it was drawn by. Only the front-most camera under the cursor is
+// considered, so overlays like minimaps cover the view below them.
+func pick(cursor rl.Vector2, drawn []input.DrawnReceiver) (entities.IEntity, input_event.Viewport) {
+	var camera input_event.Viewport
+	for i := len(drawn) - 1; i >= 0; i-- {
+		if drawn[i].Camera.ContainsScreenPoint(cursor) {
+			camera = drawn[i].Camera
+			break
+		}
+	}
+	if camera == nil {
+		return nil, nil
+	}
+
+	world := camera.ScreenToWorld(cursor)
+	for i := len(drawn) - 1; i >= 0; i-- {
+		if drawn[i].Camera != camera {
+			continue
+		}
+		entity, ok := drawn[i].Receiver.(entities.IEntity)
+		if !ok {
+			continue
+		}
+		pickable, ok := entity.(Pickable)
+		if !ok || pickable.GetHitShape() == nil {
+			continue
+		}
+		if pickable.GetHitShape().ContainsPoint(toLocal(entity, world), world) {
+			return entity, camera
+		}
+	}
+	return nil, nil
+}
+
+// newEvent creates a pointer event for the entity, as seen through the
+// camera.
+func (p *picker) newEvent(entity entities.IEntity, camera input_event.Viewport, cursor rl.Vector2) *PointerEvent {
+	world, press := cursor, p.pressPosition
+	if camera != nil {
+		world = camera.ScreenToWorld(cursor)
+		press = camera.ScreenToWorld(p.pressPosition)
+	}
+	return &PointerEvent{
+		Entity:             entity,
+		Camera:             camera,
+		ScreenPosition:     cursor,
+		WorldPosition:      world,
+		LocalPosition:      toLocal(entity, world),
+		PressPosition:      press,
+		PressLocalPosition: toLocal(entity, press),
+	}
+}
+
+// toLocal converts a world position to the local space of the entity.
+func toLocal(entity entities.IEntity, world rl.Vector2) rl.Vector2 {
+	transform := entity.GetGlobalTransform()
+	inverse, ok := transform.GenerateMatrix().Invert()
+	if !ok {
+		// the entity is scaled to zero, nothing is inside of it.
+		return rl.NewVector2(float32(math.Inf(1)), float32(math.Inf(1)))
+	}
+	return inverse.MultiplyV(world)
+}
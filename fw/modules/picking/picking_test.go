@@ -0,0 +1,183 @@
+package picking
+
+import (
+	"slices"
+	"testing"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	input_event "gorl/fw/core/input/input_event"
+	input "gorl/fw/core/input/input_handling"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// fakeViewport covers a rectangle of the screen, and its world is shifted by
+// offset.
+type fakeViewport struct {
+	bounds rl.Rectangle
+	offset rl.Vector2
+}
+
+func (v *fakeViewport) ScreenToWorld(screenPos rl.Vector2) rl.Vector2 {
+	return rl.Vector2Add(rl.Vector2Subtract(screenPos, rl.NewVector2(v.bounds.X, v.bounds.Y)), v.offset)
+}
+
+func (v *fakeViewport) ContainsScreenPoint(screenPos rl.Vector2) bool {
+	return rl.CheckCollisionPointRec(screenPos, v.bounds)
+}
+
+// box is a pickable entity logging the pointer events it gets.
+type box struct {
+	*entities.Entity
+	shape HitShape
+	log   []string
+	local rl.Vector2
+}
+
+func newBox(position rl.Vector2) *box {
+	return &box{
+		Entity: entities.NewEntity("box", position, 0, rl.Vector2One()),
+		shape:  HitRect{Rect: rl.NewRectangle(-10, -10, 20, 20)},
+	}
+}
+
+func (b *box) OnInputEvent(event *input_event.InputEvent) bool { return true }
+func (b *box) GetHitShape() HitShape                           { return b.shape }
+func (b *box) OnPointerEnter(event *PointerEvent)              { b.log = append(b.log, "enter") }
+func (b *box) OnPointerExit(event *PointerEvent)               { b.log = append(b.log, "exit") }
+func (b *box) OnClick(event *PointerEvent)                     { b.log = append(b.log, "click") }
+func (b *box) OnDragStart(event *PointerEvent)                 { b.log = append(b.log, "drag start") }
+func (b *box) OnDragEnd(event *PointerEvent)                   { b.log = append(b.log, "drag end") }
+func (b *box) OnDrag(event *PointerEvent) {
+	b.log = append(b.log, "drag")
+	b.local = event.LocalPosition
+}
+
+// frame runs a frame with the cursor at x, y, and the given actions active.
+// All drawn receivers process input.
+func frame(source *input.QueuedInputSource, drawn []input.DrawnReceiver, x, y float32, actions ...input_event.Action) {
+	receivers := []input.InputReceiver{}
+	for _, d := range drawn {
+		receivers = append(receivers, d.Receiver)
+	}
+	frameWith(source, receivers, drawn, x, y, actions...)
+}
+
+// frameWith runs a frame like frame, with the given receivers processing
+// input.
+func frameWith(source *input.QueuedInputSource, receivers []input.InputReceiver, drawn []input.DrawnReceiver, x, y float32, actions ...input_event.Action) {
+	cursor := rl.NewVector2(x, y)
+	source.Queue(input_event.NewInputEvent(input_event.ActionMouseHover, cursor))
+	for _, action := range actions {
+		source.Queue(input_event.NewInputEvent(action, cursor))
+	}
+	input.HandleInputEvents(receivers, drawn)
+	Update(receivers, drawn)
+}
+
+func TestPicking(t *testing.T) {
+	source := input.NewQueuedInputSource()
+	input.SetInputSource(source)
+	defer input.SetInputSource(nil)
+	defer func() { pickerInstance = picker{dragThreshold: 4} }()
+
+	// two overlapping boxes, the right one is drawn on top. a minimap covers
+	// the top left corner of the screen.
+	view := &fakeViewport{bounds: rl.NewRectangle(0, 0, 200, 200)}
+	minimap := &fakeViewport{bounds: rl.NewRectangle(0, 0, 20, 20)}
+	back, front := newBox(rl.NewVector2(50, 50)), newBox(rl.NewVector2(60, 50))
+	drawn := []input.DrawnReceiver{{Receiver: back, Camera: view}, {Receiver: front, Camera: view}}
+	withMinimap := append(slices.Clone(drawn), input.DrawnReceiver{Receiver: back, Camera: minimap})
+
+	frame(source, drawn, 42, 50)
+	frame(source, drawn, 55, 50)
+	if !slices.Equal(back.log, []string{"enter", "exit"}) || !slices.Equal(front.log, []string{"enter"}) {
+		t.Fatalf("front-most box should be hovered, back: %v, front: %v", back.log, front.log)
+	}
+
+	front.log = nil
+	frame(source, drawn, 60, 50, input_event.ActionClickDown, input_event.ActionClickHeld)
+	frame(source, drawn, 61, 50, input_event.ActionClickHeld)
+	frame(source, drawn, 61, 50, input_event.ActionClickUp)
+	if !slices.Equal(front.log, []string{"click"}) {
+		t.Fatalf("small movement should click, got %v", front.log)
+	}
+
+	front.log = nil
+	frame(source, drawn, 60, 50, input_event.ActionClickDown, input_event.ActionClickHeld)
+	frame(source, drawn, 100, 60, input_event.ActionClickHeld)
+	frame(source, drawn, 100, 60, input_event.ActionClickUp)
+	if !slices.Equal(front.log, []string{"exit", "drag start", "drag", "drag end"}) {
+		t.Fatalf("drag should continue outside the box, got %v", front.log)
+	}
+	if front.local != rl.NewVector2(40, 10) {
+		t.Errorf("drag should report the local position, got %v", front.local)
+	}
+
+	// the minimap is in front of the view, so picking goes through it.
+	front.log, back.log = nil, nil
+	back.SetPosition(rl.NewVector2(10, 10))
+	frame(source, withMinimap, 15, 15)
+	if !slices.Equal(back.log, []string{"enter"}) {
+		t.Fatalf("back box should be picked through the minimap, got %v", back.log)
+	}
+	if GetHovered() != back {
+		t.Errorf("hovered entity should be the back box")
+	}
+}
+
+func TestRemovedEntitiesAreForgotten(t *testing.T) {
+	source := input.NewQueuedInputSource()
+	input.SetInputSource(source)
+	defer input.SetInputSource(nil)
+	defer func() { pickerInstance = picker{dragThreshold: 4} }()
+	gem.Init()
+
+	view := &fakeViewport{bounds: rl.NewRectangle(0, 0, 200, 200)}
+	target := newBox(rl.NewVector2(50, 50))
+	gem.Append(gem.GetRoot(), target)
+	drawn := []input.DrawnReceiver{{Receiver: target, Camera: view}}
+
+	frame(source, drawn, 50, 50, input_event.ActionClickDown, input_event.ActionClickHeld)
+	frame(source, drawn, 80, 50, input_event.ActionClickHeld)
+	gem.Remove(target)
+	target.log = nil
+	frame(source, nil, 80, 50, input_event.ActionClickUp)
+	if len(target.log) != 0 || GetHovered() != nil {
+		t.Errorf("removed entity should get no more events, got %v", target.log)
+	}
+}
+
+func TestOnlyInputTargetsArePicked(t *testing.T) {
+	source := input.NewQueuedInputSource()
+	input.SetInputSource(source)
+	defer input.SetInputSource(nil)
+	defer func() { pickerInstance = picker{dragThreshold: 4} }()
+	gem.Init()
+
+	// a covered scene with a box in front of the box of the scene above it.
+	view := &fakeViewport{bounds: rl.NewRectangle(0, 0, 200, 200)}
+	covered := entities.NewEntity("covered", rl.Vector2Zero(), 0, rl.Vector2One())
+	gem.Append(gem.GetRoot(), covered)
+	gem.SetFrozen(covered, true)
+	back, front := newBox(rl.NewVector2(50, 50)), newBox(rl.NewVector2(50, 50))
+	gem.Append(gem.GetRoot(), back)
+	gem.Append(covered, front)
+	_, receivers := gem.Traverse()
+	drawn := []input.DrawnReceiver{{Receiver: back, Camera: view}, {Receiver: front, Camera: view}}
+
+	frameWith(source, receivers, drawn, 50, 50)
+	if GetHovered() != back || len(front.log) != 0 {
+		t.Fatalf("the box of the covered scene should not be picked, hovered %v", GetHovered())
+	}
+
+	// while another receiver holds the focus, nothing else can be picked.
+	other := newBox(rl.NewVector2(150, 150))
+	input.GrabFocus(other)
+	defer input.ReleaseFocus(other)
+	frameWith(source, receivers, drawn, 50, 50)
+	if GetHovered() != nil {
+		t.Errorf("only the focused receiver should be picked, hovered %v", GetHovered())
+	}
+}
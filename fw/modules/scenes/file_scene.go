@@ -0,0 +1,72 @@
+package scenes
+
+import (
+	"fmt"
+	"slices"
+
+	"gorl/fw/core/assets"
+	"gorl/fw/core/gem"
+	"gorl/fw/core/logging"
+)
+
+// This checks at compile time if the interface is implemented
+var _ IScene = (*fileScene)(nil)
+var _ AssetPreloader = (*fileScene)(nil)
+
+// fileScene is a scene whose entities are loaded from a data file, see
+// gem.LoadTree for the format.
+type fileScene struct {
+	Scene
+	path string
+}
+
+func (scn *fileScene) Init() {
+	if _, err := gem.LoadTree(scn.GetRoot(), scn.path); err != nil {
+		logging.Error("Failed to load scene file \"%v\": %v", scn.path, err)
+	}
+}
+
+// Deinit drops the file from the asset cache, so it is read again the next
+// time the scene is enabled.
+func (scn *fileScene) Deinit() {
+	assets.Unload(scn.path)
+}
+
+// GetAssets returns the scene file and the prefab files it references. Prefabs
+// nested in those are read once the scene is loaded.
+func (scn *fileScene) GetAssets() []string {
+	paths := []string{scn.path}
+	tree, err := gem.ReadTree(scn.path)
+	if err != nil {
+		// reported once the scene is loaded.
+		return paths
+	}
+	var collect func(entities []gem.EntityData)
+	collect = func(entities []gem.EntityData) {
+		for _, data := range entities {
+			if data.Prefab != "" && !slices.Contains(paths, data.Prefab) {
+				paths = append(paths, data.Prefab)
+			}
+			collect(data.Children)
+		}
+	}
+	collect(tree.Entities)
+	return paths
+}
+
+// RegisterSceneFile registers a scene that is loaded from a data file every
+// time it is enabled. All entity types used in the file must have been
+// registered with gem.RegisterType.
+func RegisterSceneFile(name string, path string) {
+	RegisterScene(name, &fileScene{path: path})
+}
+
+// SaveScene writes the current entities of an enabled scene to a data file,
+// which can be registered again with RegisterSceneFile.
+func SaveScene(name string, path string) error {
+	scene, exists := sm.scenes[name]
+	if !exists {
+		return fmt.Errorf("scene %q not found", name)
+	}
+	return gem.SaveTree(scene.GetRoot(), path)
+}
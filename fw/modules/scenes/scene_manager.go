@@ -3,12 +3,20 @@ package scenes
 import (
 	"gorl/fw/core/gem"
 	"gorl/fw/core/logging"
+	"gorl/fw/core/store"
 	"gorl/fw/util"
 )
 
 type sceneManager struct {
 	scenes         map[string]IScene
 	enabled_scenes map[string]bool
+	// the stores of the enabled scenes, see GetSceneStore.
+	stores map[string]*store.Store
+
+	// the scene stack and its changes, see PushScene.
+	stack          []stackEntry
+	pendingChanges []*sceneChange
+	activeChange   *sceneChange
 }
 
 // Create a new SceneManager. A SceneManager will automatically take care of
@@ -17,17 +25,21 @@ func newSceneManager() *sceneManager {
 	return &sceneManager{
 		scenes:         make(map[string]IScene),
 		enabled_scenes: make(map[string]bool),
+		stores:         make(map[string]*store.Store),
 	}
 }
 
 // The global instance of the SceneManager
 var sm *sceneManager = newSceneManager()
 
-// Register a scene with the SceneManager for automatic control
+// Register a scene with the SceneManager for automatic control.
+// The root entity of the scene is named after the scene, so its entities can
+// be found by path, e.g. gem.Find("name/SomeEntity").
 func RegisterScene(name string, scene IScene) {
 	if _, exists := sm.scenes[name]; exists {
 		logging.Fatal("A scene with name \"%v\" is already registered.", name)
 	}
+	scene.GetRoot().Name = name
 	sm.scenes[name] = scene
 }
 
@@ -40,6 +52,7 @@ func EnableScene(name string) {
 
 	// Initialize the scene if it's not already enabled
 	if !sm.enabled_scenes[name] {
+		sm.stores[name] = store.New()
 		gem.Append(gem.GetRoot(), scene.GetRoot())
 		scene.Init()
 		sm.enabled_scenes[name] = true
@@ -55,19 +68,32 @@ func DisableScene(name string) {
 
 	// De-initialize the scene if it's currently enabled
 	if sm.enabled_scenes[name] {
-		scene.Deinit()
-		gem.Remove(scene.GetRoot())
-		sm.enabled_scenes[name] = false
+		disableNow(name, scene)
 	}
 }
 
+// disableNow deinitializes an enabled scene, removes it from the gem and
+// discards its store.
+func disableNow(name string, scene IScene) {
+	scene.Deinit()
+	gem.Remove(scene.GetRoot())
+	sm.enabled_scenes[name] = false
+	sm.stores[name].Clear()
+	delete(sm.stores, name)
+}
+
+// GetSceneStore returns the store of an enabled scene, or nil if the scene is
+// not enabled. The store is created empty when the scene is enabled, before
+// its Init is called, and discarded when the scene is disabled.
+func GetSceneStore(name string) *store.Store {
+	return sm.stores[name]
+}
+
 // Disable all Scenes that are currently enabled.
 func DisableAllScenes() {
 	for name, _ := range sm.scenes {
 		if sm.enabled_scenes[name] {
-			sm.scenes[name].Deinit()
-			gem.Remove(sm.scenes[name].GetRoot())
-			sm.enabled_scenes[name] = false
+			disableNow(name, sm.scenes[name])
 		}
 	}
 }
@@ -77,8 +103,7 @@ func DisableAllScenes() {
 func DisableAllScenesExcept(exception_slice []string) {
 	for name, _ := range sm.scenes {
 		if sm.enabled_scenes[name] && !util.SliceContains(exception_slice, name) {
-			sm.scenes[name].Deinit()
-			sm.enabled_scenes[name] = false
+			disableNow(name, sm.scenes[name])
 		}
 	}
 }
@@ -0,0 +1,293 @@
+package scenes
+
+import (
+	"fmt"
+	"slices"
+
+	"gorl/fw/core/assets"
+	"gorl/fw/core/backend"
+	"gorl/fw/core/gem"
+	"gorl/fw/core/logging"
+)
+
+// AssetPreloader can be implemented by scenes whose assets should be read in
+// the background before the scene is enabled by PushScene or ReplaceScene.
+type AssetPreloader interface {
+	GetAssets() []string
+}
+
+// ChangeOptions control how the scene stack changes. The zero value changes
+// it instantly.
+type ChangeOptions struct {
+	// Transition covers the screen while the scenes are swapped. May be nil.
+	Transition Transition
+	// OnProgress is called every frame while the assets of the new scene are
+	// preloaded, with the share loaded so far, from 0 to 1. May be nil.
+	OnProgress func(progress float32)
+}
+
+// stackEntry is a scene on the scene stack.
+type stackEntry struct {
+	name string
+}
+
+type changePhase int
+
+const (
+	phaseCovering changePhase = iota
+	phaseLoading
+	phaseRevealing
+)
+
+// sceneChange is a pending or running change of the scene stack.
+type sceneChange struct {
+	apply   func()
+	assets  []string
+	options ChangeOptions
+
+	phase   changePhase
+	elapsed float32
+	preload *assets.Preload
+}
+
+// PushScene enables the scene on top of the scene stack. The scene below
+// stays enabled and drawn, but is no longer processed until the new scene
+// is popped again. Useful for menus and overlays. Scenes that are already
+// enabled are not pushed.
+//
+// The change is applied by Update, once the transition covers the screen and
+// the scene's assets are loaded. Changes requested while another one runs are
+// applied one after another.
+func PushScene(name string, options ChangeOptions) {
+	scene := getScene(name)
+	queueChange(func() {
+		if !canEnter(name) {
+			return
+		}
+		coverTop()
+		EnableScene(name)
+		sm.stack = append(sm.stack, stackEntry{name: name})
+	}, sceneAssets(scene), options)
+}
+
+// PopScene disables the scene on top of the scene stack, and resumes the
+// one below it. See PushScene for when the change is applied.
+func PopScene(options ChangeOptions) {
+	queueChange(func() {
+		if len(sm.stack) == 0 {
+			logging.Warning("Scene stack is empty, nothing to pop.")
+			return
+		}
+		top := sm.stack[len(sm.stack)-1]
+		sm.stack = sm.stack[:len(sm.stack)-1]
+		DisableScene(top.name)
+		uncoverTop()
+	}, nil, options)
+}
+
+// ReplaceScene disables the scene on top of the scene stack and enables the
+// given scene in its place. See PushScene for when the change is applied.
+// Other scenes that are already enabled are not entered.
+func ReplaceScene(name string, options ChangeOptions) {
+	scene := getScene(name)
+	queueChange(func() {
+		if len(sm.stack) > 0 {
+			top := sm.stack[len(sm.stack)-1]
+			if top.name != name && !canEnter(name) {
+				return
+			}
+			sm.stack = sm.stack[:len(sm.stack)-1]
+			DisableScene(top.name)
+		} else if !canEnter(name) {
+			return
+		}
+		EnableScene(name)
+		sm.stack = append(sm.stack, stackEntry{name: name})
+	}, sceneAssets(scene), options)
+}
+
+// GetSceneStack returns the names of the scenes on the scene stack, from
+// bottom to top.
+func GetSceneStack() []string {
+	names := make([]string, len(sm.stack))
+	for i, entry := range sm.stack {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// GetEnabledScenes returns the names of all enabled scenes, including the ones
+// on the scene stack, sorted by name.
+func GetEnabledScenes() []string {
+	names := []string{}
+	for name, enabled := range sm.enabled_scenes {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// RestoreScenes disables all scenes and enables the given ones right away,
+// without transitions, e.g. when a save game is loaded. The scenes of the
+// stack are enabled last, from bottom to top. Pending scene stack changes are
+// dropped.
+//
+// If a scene is not registered, or on the stack twice, an error is returned
+// and nothing changes.
+func RestoreScenes(enabled, stack []string) error {
+	for _, name := range append(slices.Clone(enabled), stack...) {
+		if _, exists := sm.scenes[name]; !exists {
+			return fmt.Errorf("scene %q is not registered", name)
+		}
+	}
+	for i, name := range stack {
+		if slices.Contains(stack[:i], name) {
+			return fmt.Errorf("scene %q is on the stack twice", name)
+		}
+	}
+
+	if change := sm.activeChange; change != nil && change.options.Transition != nil {
+		change.options.Transition.End()
+	}
+	sm.activeChange = nil
+	sm.pendingChanges = nil
+	sm.stack = nil
+	DisableAllScenes()
+
+	for _, name := range enabled {
+		if !slices.Contains(stack, name) {
+			EnableScene(name)
+		}
+	}
+	for _, name := range stack {
+		coverTop()
+		EnableScene(name)
+		sm.stack = append(sm.stack, stackEntry{name: name})
+	}
+	return nil
+}
+
+// IsChangingScenes returns true while a scene stack change is pending or
+// running.
+func IsChangingScenes() bool {
+	return sm.activeChange != nil || len(sm.pendingChanges) > 0
+}
+
+// Update advances pending scene stack changes and their transitions. It
+// should be called once per frame from the main loop. Transitions run on
+// the unscaled frame time, so they also work while the game is paused.
+func Update() {
+	if sm.activeChange == nil {
+		if len(sm.pendingChanges) == 0 {
+			return
+		}
+		sm.activeChange = sm.pendingChanges[0]
+		sm.pendingChanges = sm.pendingChanges[1:]
+		if transition := sm.activeChange.options.Transition; transition != nil {
+			transition.Begin()
+		}
+	}
+
+	change := sm.activeChange
+	transition := change.options.Transition
+	change.elapsed += backend.GetFrameTime()
+
+	switch change.phase {
+	case phaseCovering:
+		if transition != nil && change.elapsed < transition.GetDuration() {
+			transition.SetProgress(change.elapsed / transition.GetDuration())
+			return
+		}
+		if transition != nil {
+			transition.SetProgress(1)
+		}
+		if len(change.assets) > 0 {
+			change.preload = assets.StartPreload(change.assets)
+		}
+		change.phase = phaseLoading
+		fallthrough
+
+	case phaseLoading:
+		if change.preload != nil {
+			if change.options.OnProgress != nil {
+				change.options.OnProgress(change.preload.Progress())
+			}
+			if !change.preload.IsDone() {
+				return
+			}
+			if err := change.preload.Err(); err != nil {
+				logging.Error("Failed to preload scene assets: %v", err)
+			}
+		}
+		change.apply()
+		change.phase = phaseRevealing
+		change.elapsed = 0
+		fallthrough
+
+	case phaseRevealing:
+		if transition != nil && change.elapsed < transition.GetDuration() {
+			transition.SetProgress(1 - change.elapsed/transition.GetDuration())
+			return
+		}
+		if transition != nil {
+			transition.SetProgress(0)
+			transition.End()
+		}
+		sm.activeChange = nil
+	}
+}
+
+func queueChange(apply func(), paths []string, options ChangeOptions) {
+	sm.pendingChanges = append(sm.pendingChanges, &sceneChange{
+		apply:   apply,
+		assets:  paths,
+		options: options,
+	})
+}
+
+func getScene(name string) IScene {
+	scene, exists := sm.scenes[name]
+	if !exists {
+		logging.Fatal("Scene with name \"%v\" not found.", name)
+	}
+	return scene
+}
+
+func sceneAssets(scene IScene) []string {
+	if preloader, ok := scene.(AssetPreloader); ok {
+		return preloader.GetAssets()
+	}
+	return nil
+}
+
+// canEnter returns true if the scene can be entered onto the stack. Enabled
+// scenes can't, popping them later would disable a scene the stack did not
+// enable.
+func canEnter(name string) bool {
+	if sm.enabled_scenes[name] {
+		logging.Warning("Scene \"%v\" is already enabled, it is not put on the scene stack.", name)
+		return false
+	}
+	return true
+}
+
+// coverTop stops processing the scene on top of the stack, including entities
+// with their own pause mode.
+func coverTop() {
+	if len(sm.stack) == 0 {
+		return
+	}
+	top := sm.stack[len(sm.stack)-1]
+	gem.SetFrozen(sm.scenes[top.name].GetRoot(), true)
+}
+
+// uncoverTop resumes processing the scene on top of the stack.
+func uncoverTop() {
+	if len(sm.stack) == 0 {
+		return
+	}
+	top := sm.stack[len(sm.stack)-1]
+	gem.SetFrozen(sm.scenes[top.name].GetRoot(), false)
+}
@@ -0,0 +1,166 @@
+package scenes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"gorl/fw/core/assets"
+	"gorl/fw/core/backend"
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	input "gorl/fw/core/input/input_handling"
+	"gorl/fw/core/logging"
+	"gorl/fw/core/store"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type testScene struct {
+	Scene
+}
+
+func (scn *testScene) Init()   {}
+func (scn *testScene) Deinit() {}
+
+// recordingTransition records the progress it was set to.
+type recordingTransition struct {
+	progress []float32
+	ended    bool
+}
+
+func (t *recordingTransition) Begin() {}
+func (t *recordingTransition) SetProgress(progress float32) {
+	t.progress = append(t.progress, progress)
+}
+func (t *recordingTransition) End()                 { t.ended = true }
+func (t *recordingTransition) GetDuration() float32 { return 0.5 }
+
+func setupSceneTest() {
+	b := backend.NewHeadlessBackend(0.25)
+	backend.Use(b)
+	b.Init("test", rl.NewVector2(100, 100), 4)
+	gem.Init()
+	sm = newSceneManager()
+	RegisterScene("game", &testScene{})
+	RegisterScene("menu", &testScene{})
+}
+
+func TestSceneStack(t *testing.T) {
+	setupSceneTest()
+
+	PushScene("game", ChangeOptions{})
+	for IsChangingScenes() {
+		Update()
+	}
+	hud := entities.NewEntity("hud", rl.Vector2Zero(), 0, rl.Vector2One())
+	hud.SetPauseMode(entities.PauseModeAlways)
+	gem.Append(gem.Find("game"), hud)
+
+	PushScene("menu", ChangeOptions{})
+	for IsChangingScenes() {
+		Update()
+	}
+	if !reflect.DeepEqual(GetSceneStack(), []string{"game", "menu"}) {
+		t.Fatalf("unexpected scene stack %v", GetSceneStack())
+	}
+	_, receivers := gem.Traverse()
+	if !gem.IsFrozen(sm.scenes["game"].GetRoot()) || slices.Contains(receivers, input.InputReceiver(hud)) {
+		t.Errorf("expected the covered scene to stop processing, even entities with their own pause mode")
+	}
+	menuStore := GetSceneStore("menu")
+	store.SetIn(menuStore, "selected", 2)
+
+	PopScene(ChangeOptions{})
+	for IsChangingScenes() {
+		Update()
+	}
+	if !reflect.DeepEqual(GetSceneStack(), []string{"game"}) {
+		t.Fatalf("unexpected scene stack %v", GetSceneStack())
+	}
+	if sm.enabled_scenes["menu"] || gem.Find("menu") != nil {
+		t.Errorf("expected the popped scene to be disabled and removed")
+	}
+	if _, ok := store.GetIn[int](menuStore, "selected"); ok || GetSceneStore("menu") != nil {
+		t.Errorf("expected the store of the popped scene to be discarded")
+	}
+	if gem.IsFrozen(sm.scenes["game"].GetRoot()) {
+		t.Errorf("expected the uncovered scene to be processed again")
+	}
+}
+
+func TestPushEnabledScene(t *testing.T) {
+	setupSceneTest()
+	logging.Init(t.TempDir())
+
+	EnableScene("game")
+	PushScene("menu", ChangeOptions{})
+	PushScene("game", ChangeOptions{})
+	for IsChangingScenes() {
+		Update()
+	}
+	if !reflect.DeepEqual(GetSceneStack(), []string{"menu"}) {
+		t.Fatalf("an enabled scene should not be pushed, got stack %v", GetSceneStack())
+	}
+	PopScene(ChangeOptions{})
+	for IsChangingScenes() {
+		Update()
+	}
+	if !sm.enabled_scenes["game"] || gem.IsFrozen(sm.scenes["game"].GetRoot()) {
+		t.Errorf("the scene enabled outside of the stack should be left alone")
+	}
+}
+
+func TestSceneTransition(t *testing.T) {
+	setupSceneTest()
+	transition := &recordingTransition{}
+
+	PushScene("game", ChangeOptions{Transition: transition})
+	Update() // 0.25s covered
+	if sm.enabled_scenes["game"] {
+		t.Errorf("expected the scene to be enabled once the screen is covered")
+	}
+	Update() // 0.5s, fully covered, scene is swapped
+	if !sm.enabled_scenes["game"] {
+		t.Errorf("expected the scene to be enabled")
+	}
+	Update() // 0.25s revealed
+	Update() // 0.5s, fully revealed
+
+	expected := []float32{0.5, 1, 1, 0.5, 0}
+	if !reflect.DeepEqual(transition.progress, expected) || !transition.ended {
+		t.Errorf("unexpected transition progress %v, ended %v", transition.progress, transition.ended)
+	}
+	if IsChangingScenes() {
+		t.Errorf("expected the change to be finished")
+	}
+}
+
+func TestFileSceneAssets(t *testing.T) {
+	setupSceneTest()
+	dir := t.TempDir()
+	scene, prefab := filepath.Join(dir, "level.json"), filepath.Join(dir, "crate.json")
+	os.WriteFile(prefab, []byte(`{"version": 1, "entity": {"name": "crate"}}`), 0o644)
+	os.WriteFile(scene, []byte(`{"version": 1, "entities": [
+		{"prefab": "`+prefab+`"},
+		{"name": "b", "children": [{"prefab": "`+prefab+`"}]}
+	]}`), 0o644)
+	RegisterSceneFile("level", scene)
+	if paths := sceneAssets(sm.scenes["level"]); !reflect.DeepEqual(paths, []string{scene, prefab}) {
+		t.Fatalf("want the scene and its prefab, got %v", paths)
+	}
+
+	// the scene is loaded from the preloaded files.
+	gem.ClearPrefabCache()
+	if err := assets.StartPreload([]string{scene, prefab}).Wait(); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(scene)
+	os.Remove(prefab)
+	EnableScene("level")
+	if gem.Find("level/crate") == nil || gem.Find("level/b/crate") == nil {
+		t.Errorf("want the scene loaded from the preloaded files")
+	}
+}
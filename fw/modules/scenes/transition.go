@@ -0,0 +1,112 @@
+package scenes
+
+import (
+	"gorl/fw/core/render"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Transition covers the screen while the scene stack changes, see PushScene.
+type Transition interface {
+	// Begin is called before the transition starts covering the screen.
+	Begin()
+	// SetProgress is called every frame while the transition runs, going
+	// from 0 (screen fully visible) to 1 (screen fully covered) and back.
+	SetProgress(progress float32)
+	// End is called once the screen is fully visible again.
+	End()
+	// GetDuration returns the time in seconds it takes to cover the screen.
+	// Revealing it again takes the same time.
+	GetDuration() float32
+}
+
+// shaderTransition is a transition drawn by a shader that is added to every
+// camera. The shader receives the progress and a color as uniforms.
+type shaderTransition struct {
+	duration float32
+	color    rl.Color
+	source   string
+
+	shader      *rl.Shader
+	progressLoc int32
+}
+
+// NewFadeTransition returns a transition that fades the screen to the given
+// color and back.
+func NewFadeTransition(duration float32, color rl.Color) Transition {
+	return &shaderTransition{duration: duration, color: color, source: fadeShader}
+}
+
+// NewWipeTransition returns a transition that wipes the given color over the
+// screen from left to right, and uncovers it again in reverse.
+func NewWipeTransition(duration float32, color rl.Color) Transition {
+	return &shaderTransition{duration: duration, color: color, source: wipeShader}
+}
+
+func (t *shaderTransition) Begin() {
+	// without a GPU there is nothing to draw, the timing still applies.
+	if render.IsHeadless() {
+		return
+	}
+	shader := rl.LoadShaderFromMemory("", t.source)
+	t.shader = &shader
+	t.progressLoc = rl.GetShaderLocation(shader, "progress")
+
+	color := rl.ColorNormalize(t.color)
+	rl.SetShaderValue(shader, rl.GetShaderLocation(shader, "color"),
+		[]float32{color.X, color.Y, color.Z, color.W}, rl.ShaderUniformVec4)
+	rl.SetShaderValue(shader, t.progressLoc, []float32{0}, rl.ShaderUniformFloat)
+	render.AddGlobalShader(t.shader)
+}
+
+func (t *shaderTransition) SetProgress(progress float32) {
+	if t.shader == nil {
+		return
+	}
+	rl.SetShaderValue(*t.shader, t.progressLoc, []float32{progress}, rl.ShaderUniformFloat)
+}
+
+func (t *shaderTransition) End() {
+	if t.shader == nil {
+		return
+	}
+	render.RemoveGlobalShader(t.shader)
+	rl.UnloadShader(*t.shader)
+	t.shader = nil
+}
+
+func (t *shaderTransition) GetDuration() float32 {
+	return t.duration
+}
+
+const fadeShader = `#version 330
+in vec2 fragTexCoord;
+in vec4 fragColor;
+uniform sampler2D texture0;
+uniform vec4 colDiffuse;
+uniform float progress;
+uniform vec4 color;
+out vec4 finalColor;
+
+void main() {
+    vec4 texel = texture(texture0, fragTexCoord) * colDiffuse * fragColor;
+    finalColor = mix(texel, color, progress);
+}
+`
+
+const wipeShader = `#version 330
+in vec2 fragTexCoord;
+in vec4 fragColor;
+uniform sampler2D texture0;
+uniform vec4 colDiffuse;
+uniform float progress;
+uniform vec4 color;
+out vec4 finalColor;
+
+void main() {
+    vec4 texel = texture(texture0, fragTexCoord) * colDiffuse * fragColor;
+    // a soft edge that starts fully left of the screen and ends fully right.
+    float covered = 1.0 - smoothstep(progress * 1.1 - 0.1, progress * 1.1, fragTexCoord.x);
+    finalColor = mix(texel, color, covered);
+}
+`
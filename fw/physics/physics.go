@@ -16,7 +16,6 @@ type PhysicsState struct {
 	timestep           float64
 	velocityIterations int
 	positionIterations int
-	updateTimer        util.Timer
 
 	physicsWorld     box2d.B2World
 	destructionQueue []*box2d.B2Body
@@ -47,7 +46,6 @@ func InitPhysics(timestep float32, gravity rl.Vector2, simulationScale float32)
 		timestep:           float64(timestep),
 		velocityIterations: 8,
 		positionIterations: 3,
-		updateTimer:        *util.NewTimer(timestep),
 		physicsWorld:       box2d.MakeB2World(box2d.MakeB2Vec2(float64(gravity.X), float64(gravity.Y))),
 		simulationScale:    float64(simulationScale),
 	}
@@ -60,14 +58,9 @@ func DeinitPhysics() {
 	State.physicsWorld.Destroy()
 }
 
-// Update the physics world. This must be called every frame, the fixed
-// timestep is managed internally.
-// Returns true if the physics world was updated, false otherwise.
-func Update() bool {
-	if !State.updateTimer.Check() {
-		return false
-	}
-
+// Step advances the physics world by one fixed timestep. It must be called
+// once per fixed step of the game loop, see the loop package.
+func Step() {
 	State.physicsWorld.Step(State.timestep, State.velocityIterations, State.positionIterations)
 
 	// remove all bodies queued for destruction. Destroying an object while the
@@ -78,7 +71,6 @@ func Update() bool {
 		State.physicsWorld.DestroyBody(body)
 	}
 	State.destructionQueue = []*box2d.B2Body{}
-	return true
 }
 
 // ------------------
@@ -1,91 +1,385 @@
 package saving
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+	"time"
+
+	"gorl/fw/core/settings"
 )
 
+// Migration upgrades the data of a section by one version, from the version
+// it was registered for to the next. The data is the decoded JSON object of
+// the state, and is changed in place.
+type Migration func(data map[string]any) error
+
 var (
-	instance *SaveState
-	once     sync.Once
+	// ErrCorrupted is returned when a save file does not match its checksum.
+	ErrCorrupted = errors.New("save file is corrupted")
+	// ErrNoSave is returned when a slot has no save file.
+	ErrNoSave = errors.New("no save in this slot")
 )
 
-// SaveState struct now public to allow external access
-type SaveState struct {
-	Version             int32   `json:"version"`
-	TutorialSeen        bool    `json:"tutorial"`
-	UnlockedRewardIndex int32   `json:"ridx"`
-	CurrentDialogIndex  int32   `json:"didx"`
-	IceMeterValue       float32 `json:"ice"`
-}
-
-// GetInstance returns the singleton instance of SaveState
-func GetInstance() *SaveState {
-	once.Do(func() {
-		instance = &SaveState{
-			Version:             1,
-			TutorialSeen:        false,
-			UnlockedRewardIndex: -1,
-			CurrentDialogIndex:  0,
-			IceMeterValue:       100,
+// section is a registered part of the save game.
+type section struct {
+	version    int
+	migrations map[int]Migration
+	// encode returns the current state, decode reads a saved state without
+	// applying it yet, so a failed load changes nothing.
+	encode func() ([]byte, error)
+	decode func(data []byte) (apply func() error, err error)
+}
+
+var registry = struct {
+	sync.Mutex
+	sections map[string]*section
+	saveDir  string
+}{sections: make(map[string]*section)}
+
+// Register adds a state to the save game under a name. Save writes the state
+// the pointer points to, and Load replaces it. The state is encoded as JSON,
+// so it must be a struct or a map with exported or tagged fields.
+//
+// Bump the version whenever the layout of the state changes, and add a
+// Migration from the old version with AddMigration.
+func Register[T any](name string, version int, state *T) {
+	register(name, version,
+		func() ([]byte, error) { return json.Marshal(state) },
+		func(data []byte) (func() error, error) {
+			loaded := new(T)
+			if err := json.Unmarshal(data, loaded); err != nil {
+				return nil, err
+			}
+			return func() error { *state = *loaded; return nil }, nil
+		},
+	)
+}
+
+func register(name string, version int, encode func() ([]byte, error), decode func([]byte) (func() error, error)) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, exists := registry.sections[name]; exists {
+		panic(fmt.Sprintf("saving: section %q is already registered", name))
+	}
+	registry.sections[name] = &section{
+		version:    version,
+		migrations: make(map[int]Migration),
+		encode:     encode,
+		decode:     decode,
+	}
+}
+
+// AddMigration adds the migration of a registered section from the version
+// fromVersion to fromVersion+1.
+func AddMigration(name string, fromVersion int, migrate Migration) {
+	registry.Lock()
+	defer registry.Unlock()
+	s, ok := registry.sections[name]
+	if !ok {
+		panic(fmt.Sprintf("saving: section %q is not registered", name))
+	}
+	s.migrations[fromVersion] = migrate
+}
+
+// SetSaveDir sets the directory the save slots are stored in. By default,
+// this is a directory named after the title of the game in the user's
+// config directory, e.g. ~/.config/<title>/saves on Linux.
+func SetSaveDir(dir string) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.saveDir = dir
+}
+
+// GetSaveDir returns the directory the save slots are stored in.
+func GetSaveDir() (string, error) {
+	registry.Lock()
+	defer registry.Unlock()
+	return saveDir()
+}
+
+func saveDir() (string, error) {
+	if registry.saveDir != "" {
+		return registry.saveDir, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	title := ""
+	if s := settings.CurrentSettings(); s != nil {
+		title = s.Title
+	}
+	return filepath.Join(configDir, dirName(title), "saves"), nil
+}
+
+// dirName turns a title into a name that is safe to use as a directory.
+func dirName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < ' ' {
+			return '_'
 		}
-	})
-	return instance
+		return r
+	}, strings.TrimSpace(title))
+	if name == "" || name == "." || name == ".." {
+		return "gorl"
+	}
+	return name
 }
 
-// SaveGame saves the current state to a file
-func SaveGame() error {
-	state := GetInstance()
-	homeDir, err := os.UserHomeDir()
+// saveFile is the content of a save file. The checksum is the sha256 of the
+// sections, without whitespace.
+type saveFile struct {
+	SavedAt  time.Time       `json:"savedAt"`
+	Checksum string          `json:"checksum"`
+	Sections json.RawMessage `json:"sections"`
+}
+
+type savedSection struct {
+	Version int             `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// slotPath returns the path of the save file of a slot.
+func slotPath(slot string) (string, error) {
+	if slot == "" || slot != filepath.Base(slot) || strings.HasPrefix(slot, ".") {
+		return "", fmt.Errorf("invalid save slot name %q", slot)
+	}
+	dir, err := saveDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, slot+".json"), nil
+}
+
+func checksum(sections json.RawMessage) (string, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, sections); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Save writes all registered sections to the slot, replacing the save that
+// was in it. The file is written next to the old one first and then renamed,
+// so a crash while saving never leaves a broken save behind.
+func Save(slot string) error {
+	registry.Lock()
+	defer registry.Unlock()
+	path, err := slotPath(slot)
 	if err != nil {
 		return err
 	}
-	saveDir := filepath.Join(homeDir, ".snowbound")
-	os.MkdirAll(saveDir, os.ModePerm)
 
-	savePath := filepath.Join(saveDir, "savefile.json")
+	sections := make(map[string]savedSection, len(registry.sections))
+	for name, s := range registry.sections {
+		data, err := s.encode()
+		if err != nil {
+			return fmt.Errorf("saving %v: %w", name, err)
+		}
+		sections[name] = savedSection{Version: s.version, Data: data}
+	}
+	raw, err := json.Marshal(sections)
+	if err != nil {
+		return err
+	}
+	sum, err := checksum(raw)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(saveFile{SavedAt: time.Now(), Checksum: sum, Sections: raw}, "", "    ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, data)
+}
 
-	file, err := os.Create(savePath)
+// writeAtomic writes the data to a temporary file in the same directory and
+// renames it to path once it is complete.
+func writeAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// removing fails once the file was renamed, which is fine.
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load reads the save of the slot into the registered sections, migrating
+// sections saved with an older version. Sections missing from the save keep
+// their current state, saved sections that are no longer registered are
+// ignored. If any section fails to decode, no state is changed. Sections are
+// applied in the order of their names.
+func Load(slot string) error {
+	registry.Lock()
+	defer registry.Unlock()
+	file, err := readSlot(slot)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	var sections map[string]savedSection
+	if err := json.Unmarshal(file.Sections, &sections); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	applies := []func() error{}
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		saved := sections[name]
+		s, ok := registry.sections[name]
+		if !ok {
+			continue
+		}
+		data, err := migrate(name, s, saved)
+		if err != nil {
+			return err
+		}
+		apply, err := s.decode(data)
+		if err != nil {
+			return fmt.Errorf("loading %v: %w", name, err)
+		}
+		applies = append(applies, func() error {
+			if err := apply(); err != nil {
+				return fmt.Errorf("loading %v: %w", name, err)
+			}
+			return nil
+		})
+	}
+	var errs []error
+	for _, apply := range applies {
+		errs = append(errs, apply())
+	}
+	return errors.Join(errs...)
+}
+
+func readSlot(slot string) (saveFile, error) {
+	path, err := slotPath(slot)
+	if err != nil {
+		return saveFile{}, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return saveFile{}, fmt.Errorf("%w: %v", ErrNoSave, slot)
+	} else if err != nil {
+		return saveFile{}, err
+	}
+	var file saveFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return saveFile{}, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	sum, err := checksum(file.Sections)
+	if err != nil || sum != file.Checksum {
+		return saveFile{}, fmt.Errorf("%w: checksum mismatch in slot %v", ErrCorrupted, slot)
+	}
+	return file, nil
+}
 
-	encoder := json.NewEncoder(file)
-	return encoder.Encode(state)
+// migrate brings the data of a saved section up to the registered version.
+func migrate(name string, s *section, saved savedSection) ([]byte, error) {
+	if saved.Version > s.version {
+		return nil, fmt.Errorf("loading %v: saved with version %v, newer than %v", name, saved.Version, s.version)
+	}
+	if saved.Version == s.version {
+		return saved.Data, nil
+	}
+	var data map[string]any
+	if err := json.Unmarshal(saved.Data, &data); err != nil {
+		return nil, fmt.Errorf("migrating %v: %w", name, err)
+	}
+	for version := saved.Version; version < s.version; version++ {
+		migration, ok := s.migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("migrating %v: no migration from version %v", name, version)
+		}
+		if err := migration(data); err != nil {
+			return nil, fmt.Errorf("migrating %v from version %v: %w", name, version, err)
+		}
+	}
+	return json.Marshal(data)
 }
 
-// SaveExists checks if the save file exists
-func SaveExists() bool {
-	homeDir, _ := os.UserHomeDir()
-	savePath := filepath.Join(homeDir, ".snowbound", "savefile.json")
+// SlotInfo describes a save slot.
+type SlotInfo struct {
+	Name    string
+	SavedAt time.Time
+}
 
-	_, err := os.Stat(savePath)
-	return err == nil
+// ListSlots returns the slots that hold a save, the most recent save first.
+// Corrupted saves are listed as well, Load reports them.
+func ListSlots() ([]SlotInfo, error) {
+	registry.Lock()
+	defer registry.Unlock()
+	dir, err := saveDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	slots := []SlotInfo{}
+	for _, path := range paths {
+		info := SlotInfo{Name: strings.TrimSuffix(filepath.Base(path), ".json")}
+		var file saveFile
+		if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &file) == nil {
+			info.SavedAt = file.SavedAt
+		}
+		slots = append(slots, info)
+	}
+	slices.SortStableFunc(slots, func(a, b SlotInfo) int { return b.SavedAt.Compare(a.SavedAt) })
+	return slots, nil
 }
 
-// LoadGame loads the game state from a file
-func LoadGame() error {
-	homeDir, err := os.UserHomeDir()
+// Exists returns true if the slot holds a save.
+func Exists(slot string) bool {
+	registry.Lock()
+	defer registry.Unlock()
+	path, err := slotPath(slot)
 	if err != nil {
-		return err
+		return false
 	}
-	savePath := filepath.Join(homeDir, ".snowbound", "savefile.json")
+	_, err = os.Stat(path)
+	return err == nil
+}
 
-	file, err := os.Open(savePath)
+// Delete removes the save of the slot. Deleting an empty slot is no error.
+func Delete(slot string) error {
+	registry.Lock()
+	defer registry.Unlock()
+	path, err := slotPath(slot)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	var state SaveState
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&state); err != nil {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return err
 	}
-
-	*GetInstance() = state // Update the singleton instance
 	return nil
 }
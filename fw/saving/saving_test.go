@@ -0,0 +1,111 @@
+package saving
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type playerState struct {
+	Name   string `json:"name"`
+	Health int    `json:"health"`
+}
+
+// setupSaveTest clears the registry and saves to a temporary directory.
+func setupSaveTest(t *testing.T) string {
+	dir := t.TempDir()
+	registry.sections = make(map[string]*section)
+	SetSaveDir(dir)
+	t.Cleanup(func() { SetSaveDir("") })
+	return dir
+}
+
+func TestSaveAndLoadSlots(t *testing.T) {
+	dir := setupSaveTest(t)
+	player := playerState{Name: "ada", Health: 3}
+	Register("player", 1, &player)
+
+	if err := Save("slot1"); err != nil {
+		t.Fatal(err)
+	}
+	player.Health = 1
+	if err := Save("slot2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load("slot1"); err != nil {
+		t.Fatal(err)
+	}
+	if player.Health != 3 {
+		t.Errorf("want the state of slot1, got %+v", player)
+	}
+
+	slots, err := ListSlots()
+	if err != nil || len(slots) != 2 || slots[0].Name != "slot2" {
+		t.Errorf("want both slots, the latest first, got %v, %v", slots, err)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(tmp) != 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+	if err := Load("empty"); !errors.Is(err, ErrNoSave) {
+		t.Errorf("want ErrNoSave, got %v", err)
+	}
+	if err := Save("../escape"); err == nil {
+		t.Errorf("slot names must not leave the save directory")
+	}
+}
+
+func TestCorruptedSaveIsRejected(t *testing.T) {
+	dir := setupSaveTest(t)
+	player := playerState{Name: "ada", Health: 3}
+	Register("player", 1, &player)
+	if err := Save("slot"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "slot.json")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), `"health": 3`, `"health": 99`, 1)), 0o644)
+	data, _ = os.ReadFile(path)
+	if !strings.Contains(string(data), "99") {
+		t.Fatalf("test did not tamper with the save: %s", data)
+	}
+	player.Health = 1
+	if err := Load("slot"); !errors.Is(err, ErrCorrupted) || player.Health != 1 {
+		t.Errorf("tampered save should be rejected, got %v, %+v", err, player)
+	}
+}
+
+func TestMigration(t *testing.T) {
+	setupSaveTest(t)
+	// version 1 stored the health as "hp".
+	old := map[string]any{"name": "ada", "hp": 3}
+	Register("player", 1, &old)
+	if err := Save("slot"); err != nil {
+		t.Fatal(err)
+	}
+
+	registry.sections = make(map[string]*section)
+	var player playerState
+	Register("player", 2, &player)
+	AddMigration("player", 1, func(data map[string]any) error {
+		data["health"] = data["hp"]
+		delete(data, "hp")
+		return nil
+	})
+	if err := Load("slot"); err != nil {
+		t.Fatal(err)
+	}
+	if player != (playerState{Name: "ada", Health: 3}) {
+		t.Errorf("migrated state is wrong: %+v", player)
+	}
+
+	// a save of a newer version can't be loaded.
+	registry.sections = make(map[string]*section)
+	var older map[string]any
+	Register("player", 0, &older)
+	if err := Load("slot"); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("want an error for a newer save, got %v", err)
+	}
+}
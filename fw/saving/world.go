@@ -0,0 +1,229 @@
+package saving
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	"gorl/fw/modules/scenes"
+)
+
+// WorldSection is the name of the section added by RegisterWorld. Use it to
+// add migrations of the world.
+const WorldSection = "world"
+
+// Persistent is implemented by entities whose state is part of the save game,
+// see RegisterWorld. Save returns the state of the entity, Load restores it,
+// e.g. after the entity was spawned again. The data is stored as JSON, so
+// numbers are loaded as float64.
+//
+// PersistentID identifies the entity across save games, so it has to be
+// unique and must not change while the entity lives, unlike its name or
+// path. Entities spawned again on load get their ID back through
+// SetPersistentID before Load is called. Embed PersistentID to hold it.
+type Persistent interface {
+	entities.IEntity
+	GetPersistentID() string
+	SetPersistentID(id string)
+	Save() map[string]any
+	Load(data map[string]any)
+}
+
+// PersistentID holds the ID of a Persistent entity. Entities that are part
+// of a scene can use a fixed ID, entities spawned at runtime a new one, see
+// NewPersistentID.
+type PersistentID struct {
+	ID string
+}
+
+// GetPersistentID returns the ID.
+func (p *PersistentID) GetPersistentID() string { return p.ID }
+
+// SetPersistentID sets the ID.
+func (p *PersistentID) SetPersistentID(id string) { p.ID = id }
+
+// NewPersistentID returns a random ID, e.g. for entities spawned at runtime.
+func NewPersistentID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// worldState is the saved state of the gem graph.
+type worldState struct {
+	// Scenes are the enabled scenes, SceneStack the names on the scene stack
+	// from bottom to top.
+	Scenes     []string          `json:"scenes"`
+	SceneStack []string          `json:"sceneStack"`
+	Entities   []persistedEntity `json:"entities"`
+}
+
+// persistedEntity is the state of a Persistent entity.
+type persistedEntity struct {
+	ID string `json:"id"`
+	// ParentID is the ID of the parent if it is persistent, otherwise Parent
+	// is its path in the gem graph. The entity is spawned there again if it
+	// is missing.
+	ParentID string `json:"parentId,omitempty"`
+	Parent   string `json:"parent"`
+	// Entity describes the entity without its children, to spawn it again.
+	Entity gem.EntityData `json:"entity"`
+	Data   map[string]any `json:"data"`
+}
+
+// RegisterWorld adds the enabled scenes and the Persistent entities of the
+// gem graph to the save game.
+//
+// Loading restores the scenes first, which recreates their entities, and then
+// patches the entities by their ID: saved entities that exist get their
+// transform and data back, missing ones are spawned again under their saved
+// parent, and Persistent entities that were not saved are removed.
+//
+// The graph can't change during a traversal, so call Load e.g. from a queued
+// event, see event.Queue.
+func RegisterWorld() {
+	register(WorldSection, 1, encodeWorld, decodeWorld)
+}
+
+func encodeWorld() ([]byte, error) {
+	state := worldState{
+		Scenes:     scenes.GetEnabledScenes(),
+		SceneStack: scenes.GetSceneStack(),
+		Entities:   []persistedEntity{},
+	}
+
+	// the query walks the graph top-down, so parents are saved, and spawned
+	// again, before their children.
+	saved := make(map[string]entities.IEntity)
+	for _, entity := range gem.Query[Persistent](gem.GetRoot()) {
+		if gem.IsQueuedForDeletion(entity) {
+			continue
+		}
+		id := entity.GetPersistentID()
+		if id == "" {
+			return nil, fmt.Errorf("persistent entity %q has no ID", gem.GetPath(entity))
+		}
+		if other, ok := saved[id]; ok {
+			return nil, fmt.Errorf("persistent entities %q and %q have the same ID %q", gem.GetPath(other), gem.GetPath(entity), id)
+		}
+		saved[id] = entity
+
+		data, err := gem.EncodeEntity(entity)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", id, err)
+		}
+		data.Children = nil
+		persisted := persistedEntity{ID: id, Entity: data, Data: entity.Save()}
+		parent := gem.GetParent(entity)
+		if p, ok := parent.(Persistent); ok {
+			persisted.ParentID = p.GetPersistentID()
+		} else {
+			persisted.Parent = gem.GetPath(parent)
+		}
+		state.Entities = append(state.Entities, persisted)
+	}
+	return json.Marshal(state)
+}
+
+func decodeWorld(data []byte) (func() error, error) {
+	var state worldState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return func() error { return applyWorld(state) }, nil
+}
+
+// applyWorld restores the scenes and patches the entities, see RegisterWorld.
+func applyWorld(state worldState) error {
+	if gem.IsTraversing() {
+		return errors.New("can't restore the world during a traversal")
+	}
+	if err := scenes.RestoreScenes(state.Scenes, state.SceneStack); err != nil {
+		return err
+	}
+
+	existing := make(map[string]Persistent)
+	for _, entity := range gem.Query[Persistent](gem.GetRoot()) {
+		existing[entity.GetPersistentID()] = entity
+	}
+
+	var errs []error
+	restored := make(map[string]Persistent, len(state.Entities))
+	for _, persisted := range state.Entities {
+		entity, err := restoreEntity(persisted, existing[persisted.ID], restored)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", persisted.ID, err))
+			continue
+		}
+		restored[persisted.ID] = entity
+	}
+
+	// remove the persistent entities that were not saved. the ones below
+	// another such entity go with it.
+	stale := make(map[entities.IEntity]bool)
+	for id, entity := range existing {
+		if restored[id] == nil {
+			stale[entity] = true
+		}
+	}
+	for entity := range stale {
+		if !hasStaleAncestor(entity, stale) {
+			gem.Remove(entity)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func hasStaleAncestor(entity entities.IEntity, stale map[entities.IEntity]bool) bool {
+	for parent := gem.GetParent(entity); parent != nil && parent != gem.GetRoot(); parent = gem.GetParent(parent) {
+		if stale[parent] {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreEntity loads the data into the existing entity, moving it to its
+// saved parent, or spawns it again if it is missing. restored holds the
+// entities restored so far, by ID.
+func restoreEntity(persisted persistedEntity, entity Persistent, restored map[string]Persistent) (Persistent, error) {
+	var parent entities.IEntity
+	if persisted.ParentID != "" {
+		if p, ok := restored[persisted.ParentID]; ok {
+			parent = p
+		}
+	} else {
+		parent = gem.Find(persisted.Parent)
+	}
+
+	if entity != nil {
+		if parent != nil && parent != gem.GetParent(entity) {
+			gem.ReParent(entity, parent)
+		}
+		entity.SetPosition(persisted.Entity.Position)
+		entity.SetRotation(persisted.Entity.Rotation)
+		entity.SetScale(persisted.Entity.Scale)
+		entity.Load(persisted.Data)
+		return entity, nil
+	}
+
+	if parent == nil {
+		return nil, errors.New("parent not found")
+	}
+	spawned, err := gem.SpawnEntity(parent, persisted.Entity)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := spawned.(Persistent)
+	if !ok {
+		gem.Remove(spawned)
+		return nil, fmt.Errorf("entity of type %T is not persistent", spawned)
+	}
+	p.SetPersistentID(persisted.ID)
+	p.Load(persisted.Data)
+	return p, nil
+}
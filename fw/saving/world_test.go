@@ -0,0 +1,106 @@
+package saving
+
+import (
+	"reflect"
+	"testing"
+
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	"gorl/fw/modules/scenes"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type coinEntity struct {
+	*entities.Entity
+	PersistentID
+	value int
+}
+
+func newCoin(name, id string, value int) *coinEntity {
+	coin := &coinEntity{Entity: entities.NewEntity(name, rl.Vector2Zero(), 0, rl.Vector2One()), value: value}
+	coin.ID = id
+	return coin
+}
+
+func (c *coinEntity) Save() map[string]any { return map[string]any{"value": c.value} }
+func (c *coinEntity) Load(data map[string]any) {
+	c.value = int(data["value"].(float64))
+}
+
+// levelScene starts with two coins.
+type levelScene struct {
+	scenes.Scene
+}
+
+func (s *levelScene) Init() {
+	gem.Append(s.GetRoot(), newCoin("a", "a", 1))
+	gem.Append(s.GetRoot(), newCoin("b", "b", 1))
+}
+func (s *levelScene) Deinit() {}
+
+func TestSaveAndLoadWorld(t *testing.T) {
+	setupSaveTest(t)
+	gem.Init()
+	gem.RegisterType("coin", func() *coinEntity { return newCoin("", "", 0) })
+	scenes.RegisterScene("level", &levelScene{})
+	scenes.RegisterScene("menu", &levelScene{})
+	RegisterWorld()
+
+	scenes.EnableScene("level")
+	level := gem.Find("level")
+	a := gem.Find("level/a").(*coinEntity)
+	a.value = 5
+	a.SetPosition(rl.NewVector2(3, 4))
+	a.SetName("renamed")
+	gem.Remove(gem.Find("level/b"))
+	// spawned coins share their name, one is inside another.
+	c, d := newCoin("coin", NewPersistentID(), 7), newCoin("coin", NewPersistentID(), 8)
+	gem.Append(level, c)
+	gem.Append(level, d)
+	gem.ReParent(a, d)
+	if err := Save("slot"); err != nil {
+		t.Fatal(err)
+	}
+
+	scenes.DisableScene("level")
+	scenes.EnableScene("menu")
+	if err := Load("slot"); err != nil {
+		t.Fatal(err)
+	}
+
+	if enabled := scenes.GetEnabledScenes(); !reflect.DeepEqual(enabled, []string{"level"}) {
+		t.Errorf("want only the saved scene enabled, got %v", enabled)
+	}
+	coins := map[string]*coinEntity{}
+	for _, coin := range gem.Query[*coinEntity](gem.GetRoot()) {
+		coins[coin.GetPersistentID()] = coin
+	}
+	if len(coins) != 3 {
+		t.Fatalf("want the saved coins, got %v", coins)
+	}
+	if a := coins["a"]; a.value != 5 || a.GetPosition() != rl.NewVector2(3, 4) {
+		t.Errorf("want the existing coin patched, got %+v", a)
+	}
+	if a := coins["a"]; gem.GetParent(a) != coins[d.ID] {
+		t.Errorf("want the existing coin moved to its saved parent, got %v", gem.GetPath(a))
+	}
+	if coins["b"] != nil {
+		t.Errorf("want the coin that was not saved removed, got %+v", coins["b"])
+	}
+	if c, d := coins[c.ID], coins[d.ID]; c == nil || c.value != 7 || d == nil || d.value != 8 {
+		t.Errorf("want the missing coins spawned again, got %+v, %+v", c, d)
+	}
+}
+
+func TestSaveRejectsDuplicateIDs(t *testing.T) {
+	setupSaveTest(t)
+	gem.Init()
+	RegisterWorld()
+
+	gem.Append(gem.GetRoot(), newCoin("a", "same", 1))
+	gem.Append(gem.GetRoot(), newCoin("b", "same", 1))
+	if err := Save("slot"); err == nil {
+		t.Errorf("want an error for coins with the same ID")
+	}
+}
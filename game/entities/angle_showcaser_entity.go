@@ -4,6 +4,7 @@ import (
 	"gorl/fw/core/entities"
 	input "gorl/fw/core/input/input_event"
 	"gorl/fw/core/settings"
+	"gorl/fw/modules/picking"
 	"gorl/fw/util"
 	"gorl/game/code/colorscheme"
 	"math"
@@ -15,22 +16,30 @@ import (
 // Ensure that AngleShowcaserEntity implements IEntity.
 var _ entities.IEntity = &AngleShowcaserEntity{}
 
+// Ensure that AngleShowcaserEntity can be picked and dragged.
+var _ picking.Pickable = &AngleShowcaserEntity{}
+var _ picking.DragHandler = &AngleShowcaserEntity{}
+var _ picking.ClickHandler = &AngleShowcaserEntity{}
+
 // AngleShowcaser Entity
 type AngleShowcaserEntity struct {
 	*entities.Entity // Required!
 
+	// ShowcaseCircleRadius is the radius of the showcase circles.
+	ShowcaseCircleRadius float32 `prop:"showcaseCircleRadius"`
+
 	angleFuncs              []AngleFunc
-	showcaseCircleRadius    float32
 	showcaseCirclePositions []rl.Vector2
 	pointerPositions        []rl.Vector2
 	calculatedAngles        []float32
+	// dragged is the index of the showcase circle being dragged, or -1.
+	dragged int
 }
 
 // NewAngleShowcaserEntity creates a new instance of the AngleShowcaserEntity.
 func NewAngleShowcaserEntity() *AngleShowcaserEntity {
 	// NOTE: you can modify the constructor to take any parameters you need to
 	// initialize the entity.
-	radius := float32(100.0)
 	new_ent := &AngleShowcaserEntity{
 		Entity: entities.NewEntity("AngleShowcaserEntity", rl.Vector2Zero(), 0, rl.Vector2One()),
 		angleFuncs: []AngleFunc{
@@ -38,7 +47,7 @@ func NewAngleShowcaserEntity() *AngleShowcaserEntity {
 			CrossProduct2DAngleFunc,
 			Atan2AngleFunc,
 		},
-		showcaseCircleRadius: radius,
+		ShowcaseCircleRadius: 100,
 		showcaseCirclePositions: []rl.Vector2{
 			{
 				X: float32(settings.CurrentSettings().RenderWidth / 4),
@@ -54,20 +63,21 @@ func NewAngleShowcaserEntity() *AngleShowcaserEntity {
 			},
 		},
 		calculatedAngles: []float32{0, 0, 0},
-	}
-
-	for _, pos := range new_ent.showcaseCirclePositions {
-		new_ent.pointerPositions = append(
-			new_ent.pointerPositions,
-			rl.NewVector2(pos.X, pos.Y-radius),
-		)
+		dragged:          -1,
 	}
 	return new_ent
 }
 
 func (ent *AngleShowcaserEntity) Init() {
-	// Initialization logic for the entity
-	// ...
+	// the radius may have been changed after construction, e.g. when loaded
+	// from a scene file, so the pointers are placed here.
+	ent.pointerPositions = ent.pointerPositions[:0]
+	for _, pos := range ent.showcaseCirclePositions {
+		ent.pointerPositions = append(
+			ent.pointerPositions,
+			rl.NewVector2(pos.X, pos.Y-ent.ShowcaseCircleRadius),
+		)
+	}
 }
 
 func (ent *AngleShowcaserEntity) Deinit() {
@@ -115,30 +125,30 @@ Quirks:
 	determining the rotation direction.`,
 	}
 	for i := range ent.showcaseCirclePositions {
-		rl.DrawCircleV(ent.showcaseCirclePositions[i], ent.showcaseCircleRadius, colorscheme.Colorscheme.Color02.ToRGBA())
+		rl.DrawCircleV(ent.showcaseCirclePositions[i], ent.ShowcaseCircleRadius, colorscheme.Colorscheme.Color02.ToRGBA())
 		textWidth := rl.MeasureText("Approach: "+approaches[i], 20)
-		//rl.DrawText("Approach: "+approaches[i], int32(ent.showcaseCirclePositions[i].X-150), int32(ent.showcaseCirclePositions[i].Y+ent.showcaseCircleRadius+40), 20, rl.White)
+		//rl.DrawText("Approach: "+approaches[i], int32(ent.showcaseCirclePositions[i].X-150), int32(ent.showcaseCirclePositions[i].Y+ent.ShowcaseCircleRadius+40), 20, rl.White)
 		rl.DrawText("Approach: "+approaches[i],
 			int32(ent.showcaseCirclePositions[i].X-float32(textWidth)/2),
-			int32(ent.showcaseCirclePositions[i].Y+ent.showcaseCircleRadius+40),
+			int32(ent.showcaseCirclePositions[i].Y+ent.ShowcaseCircleRadius+40),
 			20, rl.White)
 		// formulas
 		textWidth = rl.MeasureText(formulas[i], 20)
 		rl.DrawText(formulas[i],
 			int32(ent.showcaseCirclePositions[i].X-float32(textWidth)/2),
-			int32(ent.showcaseCirclePositions[i].Y+ent.showcaseCircleRadius+70),
+			int32(ent.showcaseCirclePositions[i].Y+ent.ShowcaseCircleRadius+70),
 			20, rl.White)
 
 		// info text with angle range and quirks
 		rl.DrawText(infoText[i],
 			int32(ent.showcaseCirclePositions[i].X-200),
-			int32(ent.showcaseCirclePositions[i].Y+ent.showcaseCircleRadius+120),
+			int32(ent.showcaseCirclePositions[i].Y+ent.ShowcaseCircleRadius+120),
 			10, rl.White)
 
 		// reference up line
 		rl.DrawLineEx(
 			ent.showcaseCirclePositions[i],
-			rl.NewVector2(ent.showcaseCirclePositions[i].X, ent.showcaseCirclePositions[i].Y-ent.showcaseCircleRadius),
+			rl.NewVector2(ent.showcaseCirclePositions[i].X, ent.showcaseCirclePositions[i].Y-ent.ShowcaseCircleRadius),
 			3,
 			colorscheme.Colorscheme.Color01.ToRGBA(),
 		)
@@ -146,7 +156,7 @@ Quirks:
 
 	for i := range ent.pointerPositions {
 		mDir := util.Vector2NormalizeSafe(rl.Vector2Subtract(ent.pointerPositions[i], ent.showcaseCirclePositions[i]))
-		lineEnd := rl.Vector2Add(ent.showcaseCirclePositions[i], rl.Vector2Scale(mDir, ent.showcaseCircleRadius))
+		lineEnd := rl.Vector2Add(ent.showcaseCirclePositions[i], rl.Vector2Scale(mDir, ent.ShowcaseCircleRadius))
 		//rl.DrawLineV(
 		//	ent.showcaseCirclePositions[i],
 		//	lineEnd,
@@ -162,7 +172,7 @@ Quirks:
 
 	for i, angle := range ent.calculatedAngles {
 		mDir := util.Vector2NormalizeSafe(rl.Vector2Subtract(ent.pointerPositions[i], ent.showcaseCirclePositions[i]))
-		lineEnd := rl.Vector2Add(ent.showcaseCirclePositions[i], rl.Vector2Scale(mDir, ent.showcaseCircleRadius))
+		lineEnd := rl.Vector2Add(ent.showcaseCirclePositions[i], rl.Vector2Scale(mDir, ent.ShowcaseCircleRadius))
 		rl.DrawText(
 			"Angle: "+strconv.FormatFloat(float64(angle), 'f', 2, 32)+" radians",
 			int32(lineEnd.X+10),
@@ -184,21 +194,61 @@ func (ent *AngleShowcaserEntity) OnInputEvent(event *input.InputEvent) bool {
 	// Logic to run when an input event is received.
 	// Return false if the event was consumed and should not be propagated
 	// further.
+	// pointer input on the showcase circles arrives through picking.
+	return true
+}
+
+// GetHitShape returns the showcase circles, in local space.
+func (ent *AngleShowcaserEntity) GetHitShape() picking.HitShape {
+	shapes := make(picking.HitShapes, 0, len(ent.showcaseCirclePositions))
+	for _, pos := range ent.showcaseCirclePositions {
+		shapes = append(shapes, picking.HitCircle{Center: pos, Radius: ent.ShowcaseCircleRadius})
+	}
+	return shapes
+}
 
-	if event.Action == input.ActionClickHeld {
-		mPos := event.GetScreenSpaceMousePosition()
-		for idx, pos := range ent.showcaseCirclePositions {
-			if rl.CheckCollisionPointCircle(mPos, pos, ent.showcaseCircleRadius) {
-				toMouse := rl.Vector2Subtract(mPos, pos)
-				ent.pointerPositions[idx] = mPos
-				upDirection := rl.NewVector2(0, -1)
-				angle := ent.angleFuncs[idx](upDirection, toMouse)
-				ent.calculatedAngles[idx] = angle
-			}
+// OnClick points the clicked showcase circle at the pointer.
+func (ent *AngleShowcaserEntity) OnClick(event *picking.PointerEvent) {
+	if idx := ent.circleAt(event.LocalPosition); idx >= 0 {
+		ent.pointAt(idx, event.LocalPosition)
+	}
+}
+
+// OnDragStart picks the showcase circle the drag started on.
+func (ent *AngleShowcaserEntity) OnDragStart(event *picking.PointerEvent) {
+	ent.dragged = ent.circleAt(event.PressLocalPosition)
+}
+
+// OnDrag points the dragged showcase circle at the pointer, even if it left
+// the circle.
+func (ent *AngleShowcaserEntity) OnDrag(event *picking.PointerEvent) {
+	if ent.dragged >= 0 {
+		ent.pointAt(ent.dragged, event.LocalPosition)
+	}
+}
+
+func (ent *AngleShowcaserEntity) OnDragEnd(event *picking.PointerEvent) {
+	ent.dragged = -1
+}
+
+// circleAt returns the index of the showcase circle containing the local
+// position, or -1.
+func (ent *AngleShowcaserEntity) circleAt(position rl.Vector2) int {
+	for idx, pos := range ent.showcaseCirclePositions {
+		if rl.CheckCollisionPointCircle(position, pos, ent.ShowcaseCircleRadius) {
+			return idx
 		}
 	}
+	return -1
+}
 
-	return true
+// pointAt points the pointer of a showcase circle at the position, and
+// calculates its angle to the up direction.
+func (ent *AngleShowcaserEntity) pointAt(idx int, position rl.Vector2) {
+	toMouse := rl.Vector2Subtract(position, ent.showcaseCirclePositions[idx])
+	ent.pointerPositions[idx] = position
+	upDirection := rl.NewVector2(0, -1)
+	ent.calculatedAngles[idx] = ent.angleFuncs[idx](upDirection, toMouse)
 }
 
 type AngleFunc func(a, b rl.Vector2) float32
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"gorl/fw/core/backend"
 	"gorl/fw/core/datastructures"
 	"gorl/fw/core/entities"
 	"gorl/fw/core/gem"
@@ -19,6 +20,9 @@ type CameraEntity struct {
 	*entities.Entity
 	camera *render.Camera
 	ctb    *cameraTransformationBuffer
+	// destroyed is set once the render camera was destroyed in Deinit, so it
+	// is created again if the entity is added to the graph again.
+	destroyed bool
 }
 
 func NewCameraEntity(
@@ -59,16 +63,29 @@ func (ent *CameraEntity) WorldToScreen(worldPos rl.Vector2) rl.Vector2 {
 // ============================================================================
 
 func (ent *CameraEntity) Init() {
-	// Initialization logic for the entity
-	// ...
+	if ent.destroyed {
+		old := ent.camera
+		ent.camera = render.NewCamera(
+			old.GetTarget(),
+			old.GetOffset(),
+			old.GetDisplaySize(),
+			old.GetDisplayPosition(),
+			old.GetDrawFlags(),
+		)
+		ent.destroyed = false
+	}
 }
 
+// Deinit removes the render camera from the renderer, so cameras of disabled
+// scenes stop drawing.
 func (ent *CameraEntity) Deinit() {
-	// De-initialization logic for the entity
-	// ...
+	ent.camera.Destroy()
+	ent.destroyed = true
 }
 
-func (ent *CameraEntity) Update() {
+// LateUpdate runs after all entities have been updated, so the camera follows
+// the final position of whatever it is attached to in this frame.
+func (ent *CameraEntity) LateUpdate() {
 
 	// 1. Apply the absolute transform of the camera entity to the render camera.
 	absTransform := gem.GetAbsoluteTransform(ent)
@@ -98,23 +115,14 @@ func (ent *CameraEntity) OnInputEvent(event *input.InputEvent) bool {
 	const moveSpeed = 100
 	const zoomSpeed = 0.3
 
-	if event.Action == input.ActionMoveLeft {
-		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.NewVector2(-moveSpeed*rl.GetFrameTime(), 0)))
-	}
-	if event.Action == input.ActionMoveRight {
-		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.NewVector2(moveSpeed*rl.GetFrameTime(), 0)))
-	}
-	if event.Action == input.ActionMoveUp {
-		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.NewVector2(0, -moveSpeed*rl.GetFrameTime())))
-	}
-	if event.Action == input.ActionMoveDown {
-		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.NewVector2(0, moveSpeed*rl.GetFrameTime())))
+	if event.Action == input.ActionMove {
+		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.Vector2Scale(event.Axis, moveSpeed*backend.GetFrameTime())))
 	}
 	if event.Action == input.ActionZoomIn {
-		ent.SetScale(rl.NewVector2(ent.GetScale().X+zoomSpeed*rl.GetFrameTime(), 1))
+		ent.SetScale(rl.NewVector2(ent.GetScale().X+zoomSpeed*backend.GetFrameTime(), 1))
 	}
 	if event.Action == input.ActionZoomOut {
-		ent.SetScale(rl.NewVector2(ent.GetScale().X-zoomSpeed*rl.GetFrameTime(), 1))
+		ent.SetScale(rl.NewVector2(ent.GetScale().X-zoomSpeed*backend.GetFrameTime(), 1))
 	}
 
 	return true
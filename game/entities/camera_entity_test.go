@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"testing"
+
+	"gorl/fw/core/backend"
+	"gorl/fw/core/gem"
+	"gorl/fw/core/math"
+	"gorl/fw/core/render"
+	"gorl/fw/modules/scenes"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// cameraScene creates a camera every time it is enabled.
+type cameraScene struct {
+	scenes.Scene
+}
+
+func (scn *cameraScene) Init() {
+	size := rl.NewVector2(100, 100)
+	gem.Append(scn.GetRoot(), NewCameraEntity(rl.Vector2Zero(), rl.Vector2Zero(), size, rl.Vector2Zero(), math.Flag0))
+}
+func (scn *cameraScene) Deinit() {}
+
+func TestCamerasOfDisabledScenesAreDestroyed(t *testing.T) {
+	b := backend.NewHeadlessBackend(0.25)
+	backend.Use(b)
+	b.Init("test", rl.NewVector2(100, 100), 4)
+	defer b.Deinit()
+	gem.Init()
+	scenes.RegisterScene("cameras", &cameraScene{})
+
+	scenes.EnableScene("cameras")
+	scenes.DisableScene("cameras")
+	if count := len(render.GetCameras()); count != 0 {
+		t.Fatalf("want no cameras once the scene is disabled, got %v", count)
+	}
+	scenes.EnableScene("cameras")
+	if count := len(render.GetCameras()); count != 1 {
+		t.Errorf("want the camera of the enabled scene only, got %v", count)
+	}
+
+	// an entity added to the graph again gets its camera back.
+	camera := gem.Query[*CameraEntity](gem.GetRoot())[0]
+	gem.Remove(camera)
+	gem.Append(gem.GetRoot(), camera)
+	if count := len(render.GetCameras()); count != 1 {
+		t.Errorf("want the camera back, got %v", count)
+	}
+}
@@ -1,21 +1,49 @@
 package game
 
 import (
+	"gorl/fw/core/gem"
+	"gorl/fw/core/math"
+	"gorl/fw/core/settings"
 	"gorl/fw/core/store"
 	"gorl/fw/modules/scenes"
-	gscenes "gorl/game/scenes"
+	"gorl/fw/saving"
+	"gorl/game/entities"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 type ControlState struct {
-	SliderVal float32
+	SliderVal float32 `json:"sliderVal"`
 }
 
 func Init() {
-	cs := ControlState{}
-	store.Add(cs)
+	// stored as a pointer, so saving can load into it. only the main thread
+	// touches it, changes through it are not locked or watched.
+	controlState := &ControlState{}
+	store.Add(controlState)
+	// and part of every save game, see saving.Save.
+	saving.Register("controls", 1, controlState)
+	// the enabled scenes and persistent entities as well.
+	saving.RegisterWorld()
+
+	registerEntityTypes()
 
-	scenes.RegisterScene("VFH", &gscenes.VfhScene{})
-	scenes.RegisterScene("Angles", &gscenes.AnglesScene{})
+	scenes.RegisterSceneFile("Angles", "scenes/angles.json")
 
 	scenes.EnableScene("Angles")
 }
+
+// registerEntityTypes makes the game's entities available to scene files.
+func registerEntityTypes() {
+	gem.RegisterType("CameraEntity", func() *entities.CameraEntity {
+		renderSize := rl.NewVector2(
+			float32(settings.CurrentSettings().RenderWidth),
+			float32(settings.CurrentSettings().RenderHeight))
+		return entities.NewCameraEntity(
+			rl.Vector2Zero(), rl.Vector2Zero(),
+			renderSize, rl.Vector2Zero(),
+			math.Flag0,
+		)
+	})
+	gem.RegisterType("AngleShowcaserEntity", entities.NewAngleShowcaserEntity)
+}